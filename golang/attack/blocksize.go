@@ -0,0 +1,80 @@
+// Package attack holds the attacks on the oracles of sets 2 and 3: byte at
+// a time ECB decryption, cut and paste ECB, CBC bitflipping and the CBC
+// padding oracle.
+package attack
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/oracle"
+)
+
+// BlockSizeInfo describes the block cipher behind an oracle.
+type BlockSizeInfo struct {
+	InputSizeToGetFullPadding int // the size of input required to get blockSize-1 padding bytes
+	BlockSize                 int // the block size for the block cipher
+}
+
+func (bs *BlockSizeInfo) String() string {
+	return fmt.Sprintf("[BlockSizeInfo InputSizeToGetFullPadding=%d BlockSize=%d]", bs.InputSizeToGetFullPadding, bs.BlockSize)
+}
+
+// DiscoverBlockSizeInfo assumes that the encrypter function is using a block
+// cipher. You can determine the block size by incrementing the input one
+// byte at a time, and observing when the cipher text size jumps by multiple
+// bytes; ie the block size.
+func DiscoverBlockSizeInfo(oracle oracle.EncryptionOracle) BlockSizeInfo {
+	// Assume block size is 8:
+	// =>
+	// suffix | InputSizeToGetFullPadding
+	//    0   |           8
+	//    1   |           7
+	//    2   |           6
+	//    3   |           5
+	//    4   |           4
+	//    5   |           3
+	//    6   |           2
+	//    7   |           1
+	//    8   |           8
+	//    9   |           7
+
+	plainText := []byte{}
+	cipher := askOracle(oracle, plainText)
+	initialLength := len(cipher)
+	cipherLength := initialLength
+
+	for cipherLength == initialLength {
+		plainText = append(plainText, 'A')
+		cipher = askOracle(oracle, plainText)
+		cipherLength = len(cipher)
+	}
+
+	bs := cipherLength - initialLength
+	return BlockSizeInfo{
+		InputSizeToGetFullPadding: len(plainText),
+		BlockSize:                 bs,
+	}
+}
+
+// SniffEncryptionMode guesses whether cipherText was encrypted with ECB or
+// CBC, which works if the plaintext had repeated blocks in it.
+func SniffEncryptionMode(cipherText []byte) modes.Mode {
+	if modes.DetectECB(cipherText) {
+		return modes.ModeECB
+	}
+	return modes.ModeCBC
+}
+
+// CreateECBDetectingPlainText returns a byte array with duplication. As
+// Challenge 8 taught us, the problem with ECB is that it is stateless
+// and deterministic; the same 16 byte plaintext block will always
+// produce the same 16 byte ciphertext. So we create 3 blocks of the same
+// content so that we can look for a repeating pattern of 2 blocks in a
+// encrypted output. 3 blocks input means we get at least 2 blocks
+// duplicate output, even if there is some random prefix and our input
+// isn't aligned on block boundaries.
+func CreateECBDetectingPlainText(blockSize int) []byte {
+	return bytes.Repeat([]byte{'A'}, blockSize*3)
+}
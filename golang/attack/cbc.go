@@ -1,45 +1,55 @@
-package main
+package attack
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
-	"encoding/binary"
-	"fmt"
+	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
 )
 
-func newCBCPaddingOracle(plainText []byte) (
-	encrypt func() []byte,
-	isValidPadding func([]byte) bool,
-) {
-	b, _ := aes.NewCipher(newKey())
-	iv := newIv()
-	blockCipher := newAESCBCBlockCipher(b, iv)
-
-	encrypt = func() []byte {
-		in := padPKCS7([]byte(plainText), 16)
-		res, err := blockCipher.encrypt(in)
-		if err != nil {
-			panic(err)
-		}
-		// See how the attack works, at the start of attackCBCPadding...
-		// We need to return the iv as the first block, and the cipher
-		// text as the following blocks
-		return append(iv, res...)
-	}
+func xorString(a, b string) string {
+	return string(xorcipher.XOR([]byte(a), []byte(b)))
+}
 
-	isValidPadding = func(in []byte) bool {
-		out, err := blockCipher.decrypt(in)
-		if err != nil {
-			panic(err)
-		}
-		return isPKCS7Padded(out, 16)
-	}
+// MakeCBCAdminCookie flips bits in a cookie from generateCookie so that it
+// decrypts to one containing ";role=admin;".
+func MakeCBCAdminCookie(generateCookie func(string) string) string {
+	prefix := "comment1=cooking%20MCs;userdata="
+
+	// justify "0123456789ABCDEF"
+	desired := "AA;role=admin;AA"
+
+	// We need to pad the prefix so that it aligns on a block boundary
+	paddingLength := (16 - len(prefix)%16) % 16
+
+	userDataBuf := strings.Repeat("?", paddingLength+16*2)
+
+	out := generateCookie(userDataBuf)
 
-	return
+	leadingSlice := out[:paddingLength+len(prefix)]                              // the blocks containing the prefix and padding
+	targetBlock := out[paddingLength+len(prefix) : paddingLength+len(prefix)+16] // the block to attack
+	trailingSlice := out[paddingLength+len(prefix)+16:]                          // the rest of the blocks
+
+	// Insert our attack text into the block
+	// pt1: PPPPPPPPPPPPPPPP PPPPPPPPP??????? AAAAAAAAAAAAAAAA AAAAAAAAAAAAAAAA
+	// ct1: 1111111111111111 2222222222222222 3333333333333333 4444444444444444
+	// tgt:                                                    AA;admin=true;AA
+	// msg: 1111111111111111 2222222222222222 (AAAAAAAAAAAAAAAA ^ AA;admin=true;AA) 4444444444444444
+	// pt2: PPPPPPPPPPPPPPPP PPPPPPPPP??????? !!!!!!!!!!!!!!!! (AAAAAAAAAAAAAAAA ^ (AAAAAAAAAAAAAAAA ^ AA;admin=true;AA))
+	//
+	// in CBC mode, a 1-bit error in a ciphertext block:
+	// * Completely scrambles the block the error occurs in
+	// * Produces the identical 1-bit error(/edit) in the next ciphertext block.
+	//
+	// pt2 will contain the desired attack text, since XORing twice is the identity operation.
+	targetBlock = xorString(targetBlock, xorString(strings.Repeat("?", 16), desired))
+
+	return leadingSlice + targetBlock + trailingSlice
 }
 
-func attackCBCPadding(encrypted []byte, isValidPadding func([]byte) bool) []byte {
+// CBCPadding decrypts encrypted, whose first block is the IV, one byte at a
+// time using nothing but whether isValidPadding accepts what we send it.
+func CBCPadding(encrypted []byte, isValidPadding func([]byte) bool) []byte {
 	// From https://en.wikipedia.org/wiki/Block_cipher_mode_of_operation#Cipher_Block_Chaining_(CBC)
 	// We only need 2 blocks:
 	// * the second block will contain the PKCS7 padding
@@ -132,74 +142,3 @@ func attackCBCPadding(encrypted []byte, isValidPadding func([]byte) bool) []byte
 
 	return plainText
 }
-
-func decryptCTR(b cipher.Block, ct, nonce []byte) []byte {
-	var out []byte
-
-	if len(nonce) >= b.BlockSize() {
-		panic("nonce cannot be larger than the block size")
-	}
-
-	src, dst := make([]byte, b.BlockSize()), make([]byte, b.BlockSize())
-	copy(src, nonce)
-
-	for i := 0; i < len(ct); i += b.BlockSize() {
-		b.Encrypt(dst, src)
-		out = append(out, xor(dst, ct[i:])...)
-
-		// Increment the 64 bit little endian block count
-		binary.LittleEndian.PutUint64(src[8:], binary.LittleEndian.Uint64(src[8:])+1)
-	}
-
-	return out
-}
-
-var encryptCtr = decryptCTR
-
-func findFixedNonceKeyBySubstitution(plaintexts, ciphertext [][]byte) []byte {
-	res := make([]byte, 0)
-
-	for i := 0; i < 16; i++ {
-		bestScore, byteScore := 0, 0
-		var bestGuess byte
-
-		for guess := 0; guess < 256; guess++ {
-			byteScore = 0
-
-			for _, ct := range ciphertext {
-				plain := ct[i] ^ byte(guess)
-				if isEnglishCharacter(plain) {
-					byteScore++
-				}
-			}
-
-			if byteScore > bestScore {
-				bestScore = byteScore
-				bestGuess = byte(guess)
-			}
-		}
-
-		res = append(res, bestGuess)
-	}
-	// for i, text := range plaintexts {
-	// 	res = make([]byte, 0)
-	// 	for j := 0; j < 16; j++ {
-	// 		guess := text[j] ^ ciphertext[i][j]
-	// 		res = append(res, guess)
-	// 	}
-
-	// 	println(fmt.Sprintf("From plain text %d, guess is %v", i, res))
-
-	// 	res = make([]byte, 0)
-	// 	for j := 16; j < 32; j++ {
-	// 		guess := text[j] ^ ciphertext[i][j]
-	// 		res = append(res, guess)
-	// 	}
-
-	// 	println(fmt.Sprintf("From plain text %d, guess is %v", i, res))
-	// }
-
-	println(fmt.Sprintf("guess is %v", res))
-
-	return res
-}
@@ -0,0 +1,37 @@
+package attack
+
+import (
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// FindFixedNonceKeyBySubstitution guesses the first 16 bytes of the
+// keystream for ciphertexts encrypted under CTR with a fixed nonce, by
+// picking the byte which gives the most English looking text.
+func FindFixedNonceKeyBySubstitution(plaintexts, ciphertext [][]byte) []byte {
+	res := make([]byte, 0)
+
+	for i := 0; i < 16; i++ {
+		bestScore, byteScore := 0, 0
+		var bestGuess byte
+
+		for guess := 0; guess < 256; guess++ {
+			byteScore = 0
+
+			for _, ct := range ciphertext {
+				plain := ct[i] ^ byte(guess)
+				if xorcipher.IsEnglishCharacter(plain) {
+					byteScore++
+				}
+			}
+
+			if byteScore > bestScore {
+				bestScore = byteScore
+				bestGuess = byte(guess)
+			}
+		}
+
+		res = append(res, bestGuess)
+	}
+
+	return res
+}
@@ -1,13 +1,18 @@
-package main
+package attack
 
 import (
 	"bytes"
-	"crypto/aes"
 	"fmt"
 	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/oracle"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
 )
 
-func discoverSuffix(blockSizeInfo BlockSizeInfo, oracle EncryptionOracleFn) []byte {
+// DiscoverSuffix recovers the secret which oracle appends to its input
+// before encrypting it under ECB.
+func DiscoverSuffix(blockSizeInfo BlockSizeInfo, oracle oracle.EncryptionOracle) []byte {
 	// Knowing the block size, craft an input block that is exactly 1 byte
 	// short (for instance, if the block size is 8 bytes, make "AAAAAAA").
 	// Think about what the oracle function is going to put in that last
@@ -32,7 +37,7 @@ func discoverSuffix(blockSizeInfo BlockSizeInfo, oracle EncryptionOracleFn) []by
 	// should be 12 so that the first byte of suffix is the last byte of the
 	// second block for the input
 
-	bs := blockSizeInfo.blockSize
+	bs := blockSizeInfo.BlockSize
 
 	known := []byte{}
 
@@ -63,8 +68,8 @@ func discoverSuffix(blockSizeInfo BlockSizeInfo, oracle EncryptionOracleFn) []by
 		}
 
 		// if the output is pkcs7 padded then we are done
-		if isPKCS7Padded(known, bs) {
-			known = unpadPKCS7(known)
+		if padding.IsPKCS7Padded(known, bs) {
+			known = padding.UnpadPKCS7(known)
 			break
 		}
 
@@ -74,32 +79,34 @@ func discoverSuffix(blockSizeInfo BlockSizeInfo, oracle EncryptionOracleFn) []by
 	return known
 }
 
-func discoverSuffixWithRandomPrefix(blockSizeInfo BlockSizeInfo, oracle EncryptionOracleFn) []byte {
+// DiscoverSuffixWithRandomPrefix is DiscoverSuffix for an oracle which also
+// puts a fixed length random prefix in front of its input.
+func DiscoverSuffixWithRandomPrefix(blockSizeInfo BlockSizeInfo, oracle oracle.EncryptionOracle) []byte {
 	// AES-128-ECB(random-prefix || attacker-controlled || target-bytes, random-key)
 	//
 	// The input `random-prefix || attacker-controlled || target-bytes` will be
 	// padded to a multiple of block size.
 
-	bs := blockSizeInfo.blockSize
+	bs := blockSizeInfo.BlockSize
 
-	prefixSize := discoverPrefixSize(bs, oracle)
+	prefixSize := DiscoverPrefixSize(bs, oracle)
 
 	// We know how long the prefix is. So we know how long to make attacker-controlled so
 	// that the first byte of target-bytes is part of a block
-	return discoverSuffix(blockSizeInfo, func(in []byte) ([]byte, encryptionMode) {
+	return DiscoverSuffix(blockSizeInfo, func(in []byte) ([]byte, modes.Mode) {
 		p := bs - prefixSize%bs
 		msg := append(bytes.Repeat([]byte{'A'}, p), in...)
 		out := askOracle(oracle, msg)
-		return out[prefixSize+p:], MODE_ECB
+		return out[prefixSize+p:], modes.ModeECB
 	})
 }
 
-// discoverPrefixSize tries to determine how long the prefix is in the EncryptionOracleFn.
+// DiscoverPrefixSize tries to determine how long the prefix is in the EncryptionOracle.
 // returns the prefix size in bytes
-func discoverPrefixSize(bs int, oracle EncryptionOracleFn) int {
+func DiscoverPrefixSize(bs int, oracle oracle.EncryptionOracle) int {
 	// We can find out how long the random prefix is by:
 	// - create an n block long attack text
-	attackText := createECBDetectingPlainText(bs)
+	attackText := CreateECBDetectingPlainText(bs)
 
 	// - search for a n block long cipher text out
 	for i := 0; i < bs; i++ {
@@ -109,7 +116,7 @@ func discoverPrefixSize(bs int, oracle EncryptionOracleFn) int {
 		// - keep prepending a padding byte until we find a n block long cipher text
 		cipherText := askOracle(oracle, plainText)
 
-		blockText, location := findRepeatingBlock(cipherText, bs, len(attackText)/bs)
+		blockText, location := FindRepeatingBlock(cipherText, bs, len(attackText)/bs)
 
 		if location != -1 {
 			// - change the attack text content (but not the prefix padding) and confirm that
@@ -117,7 +124,7 @@ func discoverPrefixSize(bs int, oracle EncryptionOracleFn) int {
 			attackText = bytes.Repeat([]byte{'B'}, len(attackText))
 			plainText := append(padding, attackText...)
 			cipherText := askOracle(oracle, plainText)
-			newBlock, newLocation := findRepeatingBlock(cipherText, bs, len(attackText)/bs)
+			newBlock, newLocation := FindRepeatingBlock(cipherText, bs, len(attackText)/bs)
 			if newLocation == location && !bytes.Equal(blockText, newBlock) {
 				// profit!
 				return location*bs - i
@@ -130,10 +137,10 @@ func discoverPrefixSize(bs int, oracle EncryptionOracleFn) int {
 	panic("Could not determine the prefix size")
 }
 
-// findRepeatingBlock returns the first repeating block of count blocks
+// FindRepeatingBlock returns the first repeating block of count blocks
 // of blockSize, or -1 if there isn't one.
 // For example, with a blockSize of 16, find 3 repeating blocks.
-func findRepeatingBlock(buf []byte, blockSize int, count int) (content []byte, location int) {
+func FindRepeatingBlock(buf []byte, blockSize int, count int) (content []byte, location int) {
 	if len(buf)%blockSize != 0 {
 		panic("Need multiple of block size")
 	}
@@ -172,7 +179,7 @@ func attackTextSize(knownSize, blockSize int) int {
 }
 
 // makeDict returns a map of byte values keyed by hash key of a cipher block.
-func makeDict(oracle EncryptionOracleFn, blockSize int, known []byte) map[string]byte {
+func makeDict(oracle oracle.EncryptionOracle, blockSize int, known []byte) map[string]byte {
 	res := make(map[string]byte)
 
 	msg := bytes.Repeat([]byte{'A'}, blockSize)
@@ -190,7 +197,7 @@ func makeDict(oracle EncryptionOracleFn, blockSize int, known []byte) map[string
 	return res
 }
 
-func askOracle(oracle EncryptionOracleFn, msg []byte) []byte {
+func askOracle(oracle oracle.EncryptionOracle, msg []byte) []byte {
 	out, _ := oracle(msg)
 	return out
 }
@@ -200,7 +207,9 @@ func hashKeyFromBytes(buf []byte) string {
 	return string(buf)
 }
 
-func elevateToAdmin(encryptUserProfile func(string) []byte, decryptAndGetRole func([]byte) string) string {
+// ElevateToAdmin cuts and pastes ECB encrypted profiles together to make
+// one with the admin role, and returns the role it ends up with.
+func ElevateToAdmin(encryptUserProfile func(string) []byte, decryptAndGetRole func([]byte) string) string {
 	start := "email="
 
 	genBlock := func(prefix string) string {
@@ -217,80 +226,3 @@ func elevateToAdmin(encryptUserProfile func(string) []byte, decryptAndGetRole fu
 
 	return decryptAndGetRole([]byte(elevatedProfile))
 }
-
-func newCBCCookieOracles() (
-	generateCookie func(string) string,
-	amIAdmin func(string) bool,
-) {
-	// Generate a random AES key.
-	b, _ := aes.NewCipher(newKey())
-	iv := newIv()
-	blockCipher := newAESCBCBlockCipher(b, iv)
-
-	generateCookie = func(userdata string) string {
-		// The function should quote out the ";" and "=" characters.
-		userdata = strings.Replace(userdata, ";", "%3B", -1)
-		userdata = strings.Replace(userdata, "=", "%3D", -1)
-
-		// The first function should take an arbitrary input string, prepend the string:
-		//
-		// "comment1=cooking%20MCs;userdata="
-		//
-		// and append the string:
-		//
-		// ";comment2=%20like%20a%20pound%20of%20bacon"
-		msg := "comment1=cooking%20MCs;userdata=" + userdata + ";comment2=%20like%20a%20pound%20of%20bacon"
-
-		// The function should then pad out the input to the 16-byte AES block length and encrypt it under the random AES key.
-		out, _ := blockCipher.encrypt(padPKCS7([]byte(msg), 16))
-		return string(out)
-	}
-
-	amIAdmin = func(in string) bool {
-		// The second function should decrypt the string
-		msg := []byte(in)
-		out, _ := blockCipher.decrypt(msg)
-		// and look for the characters ";role=admin;"
-		return bytes.Contains(out, []byte(";role=admin;"))
-	}
-
-	return
-}
-
-func xorString(a, b string) string {
-	return string(xor([]byte(a), []byte(b)))
-}
-
-func makeCBCAdminCookie(generateCookie func(string) string) string {
-	prefix := "comment1=cooking%20MCs;userdata="
-
-	// justify "0123456789ABCDEF"
-	desired := "AA;role=admin;AA"
-
-	// We need to pad the prefix so that it aligns on a block boundary
-	paddingLength := (16 - len(prefix)%16) % 16
-
-	userDataBuf := strings.Repeat("?", paddingLength+16*2)
-
-	out := generateCookie(userDataBuf)
-
-	leadingSlice := out[:paddingLength+len(prefix)]                              // the blocks containing the prefix and padding
-	targetBlock := out[paddingLength+len(prefix) : paddingLength+len(prefix)+16] // the block to attack
-	trailingSlice := out[paddingLength+len(prefix)+16:]                          // the rest of the blocks
-
-	// Insert our attack text into the block
-	// pt1: PPPPPPPPPPPPPPPP PPPPPPPPP??????? AAAAAAAAAAAAAAAA AAAAAAAAAAAAAAAA
-	// ct1: 1111111111111111 2222222222222222 3333333333333333 4444444444444444
-	// tgt:                                                    AA;admin=true;AA
-	// msg: 1111111111111111 2222222222222222 (AAAAAAAAAAAAAAAA ^ AA;admin=true;AA) 4444444444444444
-	// pt2: PPPPPPPPPPPPPPPP PPPPPPPPP??????? !!!!!!!!!!!!!!!! (AAAAAAAAAAAAAAAA ^ (AAAAAAAAAAAAAAAA ^ AA;admin=true;AA))
-	//
-	// in CBC mode, a 1-bit error in a ciphertext block:
-	// * Completely scrambles the block the error occurs in
-	// * Produces the identical 1-bit error(/edit) in the next ciphertext block.
-	//
-	// pt2 will contain the desired attack text, since XORing twice is the identity operation.
-	targetBlock = xorString(targetBlock, xorString(strings.Repeat("?", 16), desired))
-
-	return leadingSlice + targetBlock + trailingSlice
-}
@@ -0,0 +1,99 @@
+// Package cbcmac implements CBC-MAC, and shows why it makes a poor hash
+// function.
+package cbcmac
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// hashKey is the fixed, public key which turns CBC-MAC into a hash
+// function. Since everyone knows the key, anyone can run the block cipher
+// backwards, which is what makes this such a bad idea.
+var hashKey = []byte("YELLOW SUBMARINE")
+
+// MAC returns the last block of the PKCS#7 padded CBC encryption of msg.
+func MAC(b cipher.Block, iv, msg []byte) []byte {
+	bs := b.BlockSize()
+	out, err := modes.NewCBCCipher(b, iv, padding.PKCS7).Seal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return out[len(out)-bs:]
+}
+
+// Hash uses CBC-MAC with a fixed key and a zero IV as a hash function.
+func Hash(msg []byte) []byte {
+	b, _ := aes.NewCipher(hashKey)
+	return MAC(b, make([]byte, b.BlockSize()), msg)
+}
+
+// ForgeHash returns a printable message which starts with payload and
+// whose Hash is hash. payload is followed by a JavaScript line comment so
+// that the rest of the message, which is chosen to steer the hash, won't get
+// in the way of anything executing it.
+func ForgeHash(hash, payload []byte) []byte {
+	b, _ := aes.NewCipher(hashKey)
+	bs := b.BlockSize()
+
+	// The forged message will be:
+	//
+	// payload || "//" || spaces || filler || glue
+	//
+	// where glue is a whole block, so the hash function appends a full block
+	// of padding. Working backwards from the hash, the chaining value after
+	// glue has to be D(hash) ^ padding, and so glue has to decrypt to that
+	// chaining value XORed with the chaining value after filler.
+	want := make([]byte, bs)
+	b.Decrypt(want, hash)
+	want = xorcipher.XOR(want, padding.PadPKCS7(nil, bs))
+	b.Decrypt(want, want)
+
+	prefix := append(append([]byte{}, payload...), "//"...)
+	for len(prefix)%bs != 0 {
+		prefix = append(prefix, ' ')
+	}
+	out, _ := modes.NewCBCCipher(b, make([]byte, bs), padding.None).Seal(prefix)
+	state := out[len(out)-bs:]
+
+	// glue is effectively random, and is printable with probability around
+	// 2^-23, so keep trying different printable filler blocks until it is.
+	filler, glue := make([]byte, bs), make([]byte, bs)
+	for n := uint64(0); ; n++ {
+		fillPrintable(filler, n)
+		for i := range glue {
+			glue[i] = filler[i] ^ state[i]
+		}
+		b.Encrypt(glue, glue)
+		for i := range glue {
+			glue[i] ^= want[i]
+		}
+		if isPrintable(glue) {
+			break
+		}
+	}
+
+	return append(append(prefix, filler...), glue...)
+}
+
+// fillPrintable writes n into buf in base 95, using the printable ASCII
+// characters as digits.
+func fillPrintable(buf []byte, n uint64) {
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = byte(0x20 + n%(0x7f-0x20))
+		n /= 0x7f - 0x20
+	}
+}
+
+func isPrintable(buf []byte) bool {
+	for _, c := range buf {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
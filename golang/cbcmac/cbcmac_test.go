@@ -0,0 +1,21 @@
+package cbcmac
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge50(t *testing.T) {
+	snippet := []byte("alert('MZA who was that?');\n")
+	hash := Hash(snippet)
+	testutil.AssertEqual(t, "296b8d7cb78a243dda4d0a61d33bbdd1", hex.EncodeToString(hash))
+
+	payload := []byte("alert('Ayo, the Wu is back!');")
+	forged := ForgeHash(hash, payload)
+	testutil.AssertEqual(t, hash, Hash(forged))
+	testutil.AssertEqual(t, true, bytes.HasPrefix(forged, payload))
+	testutil.AssertEqual(t, true, isPrintable(forged))
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/codec"
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+var commands = map[string]command{
+	"hex2b64": {
+		help:    "convert hex to base64 (challenge 1)",
+		inform:  "raw",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			return func(msgs [][]byte, out *output) error {
+				for _, m := range msgs {
+					b64, err := codec.Hex2Base64(strings.TrimSpace(string(m)))
+					if err != nil {
+						return err
+					}
+					if _, err := fmt.Fprintln(out.w, b64); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		},
+	},
+
+	"xor": {
+		help:    "XOR the input with a repeating key (challenges 2 and 5)",
+		inform:  "raw",
+		outform: "hex",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			keyHex := fs.String("key", "", "the key, in hex")
+			return func(msgs [][]byte, out *output) error {
+				key, err := requiredHexFlag("key", *keyHex)
+				if err != nil {
+					return err
+				}
+				return each(msgs, out, func(m []byte) ([]byte, error) {
+					return xorcipher.RepeatingKey(m, key), nil
+				})
+			}
+		},
+	},
+
+	"single-xor": {
+		help:    "find the single byte XOR key which gives the most English-looking plaintext (challenges 3 and 4)",
+		inform:  "hex",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			return func(msgs [][]byte, out *output) error {
+				var best []byte
+				bestScore, bestKey, bestLine := 0, byte(0), 0
+				for i, m := range msgs {
+					res, score, key := xorcipher.FindSingleByteKey(m)
+					if score > bestScore {
+						best, bestScore, bestKey, bestLine = res, score, key, i+1
+					}
+				}
+				if best == nil {
+					return errors.New("nothing looks like English")
+				}
+				if len(msgs) > 1 {
+					out.notef("line %d", bestLine)
+				}
+				out.notef("key %#02x, score %d", bestKey, bestScore)
+				return out.data(best)
+			}
+		},
+	},
+
+	"break-repeating-xor": {
+		help:    "recover the key of repeating key XOR and decrypt (challenge 6)",
+		inform:  "base64",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			keySize := fs.Int("keysize", 0, "the key size, or 0 to guess it")
+			return func(msgs [][]byte, out *output) error {
+				return each(msgs, out, func(m []byte) ([]byte, error) {
+					size := *keySize
+					if size == 0 {
+						size = xorcipher.FindKeySize(m)
+					}
+					key := xorcipher.FindRepeatingKey(m, size)
+					out.notef("key %q", key)
+					return xorcipher.RepeatingKey(m, key), nil
+				})
+			}
+		},
+	},
+
+	"detect-ecb": {
+		help:    "find the lines of the input which look like they were encrypted with ECB (challenge 8)",
+		inform:  "hex",
+		outform: "hex",
+		lines:   true,
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			return func(msgs [][]byte, out *output) error {
+				for i, m := range msgs {
+					if len(m)%aes.BlockSize != 0 || !modes.DetectECB(m) {
+						continue
+					}
+					out.notef("line %d", i+1)
+					if err := out.data(m); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		},
+	},
+
+	"ecb-decrypt": {
+		help:    "decrypt AES-ECB and strip the padding (challenge 7)",
+		inform:  "base64",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			keyHex := fs.String("key", "", "the AES key, in hex")
+			paddingName := paddingFlag(fs)
+			return func(msgs [][]byte, out *output) error {
+				key, err := requiredHexFlag("key", *keyHex)
+				if err != nil {
+					return err
+				}
+				scheme, err := lookupPadding(*paddingName)
+				if err != nil {
+					return err
+				}
+				b, err := aes.NewCipher(key)
+				if err != nil {
+					return err
+				}
+				return each(msgs, out, modes.NewECBCipher(b, scheme).Open)
+			}
+		},
+	},
+
+	"cbc-decrypt": {
+		help:    "decrypt AES-CBC and strip the padding (challenge 10)",
+		inform:  "base64",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			keyHex := fs.String("key", "", "the AES key, in hex")
+			ivHex := fs.String("iv", strings.Repeat("00", aes.BlockSize), "the IV, in hex")
+			paddingName := paddingFlag(fs)
+			return func(msgs [][]byte, out *output) error {
+				key, err := requiredHexFlag("key", *keyHex)
+				if err != nil {
+					return err
+				}
+				iv, err := hexFlag("iv", *ivHex)
+				if err != nil {
+					return err
+				}
+				if len(iv) != aes.BlockSize {
+					return fmt.Errorf("-iv: need %d bytes, got %d", aes.BlockSize, len(iv))
+				}
+				scheme, err := lookupPadding(*paddingName)
+				if err != nil {
+					return err
+				}
+				b, err := aes.NewCipher(key)
+				if err != nil {
+					return err
+				}
+				return each(msgs, out, modes.NewCBCCipher(b, iv, scheme).Open)
+			}
+		},
+	},
+
+	"ctr": {
+		help:    "encrypt or decrypt AES-CTR, by default with a 64 bit little endian counter after the nonce (challenge 18)",
+		inform:  "raw",
+		outform: "raw",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			keyHex := fs.String("key", "", "the AES key, in hex")
+			nonceHex := fs.String("nonce", strings.Repeat("00", 8), "the nonce, in hex, padded with zeros up to the counter")
+			bits := fs.Int("bits", 64, "the size of the counter at the end of the block, in bits")
+			bigEndian := fs.Bool("big-endian", false, "count big endian, as NIST and GCM do")
+			initial := fs.Uint64("initial", 0, "the first value of the counter")
+			return func(msgs [][]byte, out *output) error {
+				key, err := requiredHexFlag("key", *keyHex)
+				if err != nil {
+					return err
+				}
+				nonce, err := hexFlag("nonce", *nonceHex)
+				if err != nil {
+					return err
+				}
+				if *bits < 8 || *bits%8 != 0 || *bits > 8*aes.BlockSize {
+					return fmt.Errorf("-bits: must be a multiple of 8 up to %d, got %d", 8*aes.BlockSize, *bits)
+				}
+				room := aes.BlockSize - *bits/8
+				if len(nonce) > room {
+					return fmt.Errorf("-nonce: can be at most %d bytes with a %d bit counter, got %d", room, *bits, len(nonce))
+				}
+				layout := modes.CounterLayout{Bits: *bits, LittleEndian: !*bigEndian}
+				iv, err := modes.CounterBlock(append(nonce, make([]byte, room-len(nonce))...), layout, *initial)
+				if err != nil {
+					return err
+				}
+				b, err := aes.NewCipher(key)
+				if err != nil {
+					return err
+				}
+				c, err := modes.NewCounterMode(b, iv, layout)
+				if err != nil {
+					return err
+				}
+				return each(msgs, out, c.Encrypt)
+			}
+		},
+	},
+
+	"encrypt": {
+		help:    "encrypt a file of any size with AES in ECB, CBC or CTR mode, a chunk at a time",
+		inform:  "raw",
+		outform: "raw",
+		stream:  cryptFile(false),
+	},
+
+	"decrypt": {
+		help:    "decrypt a file of any size with AES in ECB, CBC or CTR mode, a chunk at a time",
+		inform:  "raw",
+		outform: "raw",
+		stream:  cryptFile(true),
+	},
+
+	"pad": {
+		help:    "pad to a whole number of blocks (challenge 9)",
+		inform:  "raw",
+		outform: "hex",
+		flags: func(fs *flag.FlagSet) func([][]byte, *output) error {
+			size := fs.Int("size", aes.BlockSize, "the block size")
+			paddingName := paddingFlag(fs)
+			return func(msgs [][]byte, out *output) error {
+				if *size < 1 || *size > 255 {
+					return fmt.Errorf("-size: must be between 1 and 255, got %d", *size)
+				}
+				scheme, err := lookupPadding(*paddingName)
+				if err != nil {
+					return err
+				}
+				if scheme == padding.PKCS5 && *size != 8 {
+					return fmt.Errorf("-size: PKCS#5 padding needs 8 byte blocks")
+				}
+				return each(msgs, out, func(m []byte) ([]byte, error) {
+					return scheme.Pad(m, *size), nil
+				})
+			}
+		},
+	},
+}
+
+// cryptFile is the encrypt and decrypt commands, which stream their input
+// through the cipher rather than reading it all in first. In CTR mode, the
+// IV is the first counter block, and the whole block counts up, big endian,
+// as NIST and the standard library have it.
+func cryptFile(decrypt bool) func(fs *flag.FlagSet) func(io.Reader, io.Writer) error {
+	return func(fs *flag.FlagSet) func(io.Reader, io.Writer) error {
+		modeName := fs.String("mode", "cbc", "the mode: ecb, cbc or ctr")
+		keyHex := fs.String("key", "", "the AES key, in hex")
+		ivHex := fs.String("iv", strings.Repeat("00", aes.BlockSize), "the IV, or the first counter block for CTR, in hex")
+		paddingName := paddingFlag(fs)
+		return func(in io.Reader, out io.Writer) error {
+			key, err := requiredHexFlag("key", *keyHex)
+			if err != nil {
+				return err
+			}
+			iv, err := hexFlag("iv", *ivHex)
+			if err != nil {
+				return err
+			}
+			if len(iv) != aes.BlockSize {
+				return fmt.Errorf("-iv: need %d bytes, got %d", aes.BlockSize, len(iv))
+			}
+			scheme, err := lookupPadding(*paddingName)
+			if err != nil {
+				return err
+			}
+			b, err := aes.NewCipher(key)
+			if err != nil {
+				return err
+			}
+
+			var mode cipher.BlockMode
+			switch *modeName {
+			case "ecb":
+				mode = modes.NewECBEncrypter(b)
+				if decrypt {
+					mode = modes.NewECBDecrypter(b)
+				}
+			case "cbc":
+				mode = modes.NewCBCEncrypter(b, iv)
+				if decrypt {
+					mode = modes.NewCBCDecrypter(b, iv)
+				}
+			case "ctr":
+				s, err := modes.NewCounterStream(b, iv, modes.CounterLayout{Bits: 8 * aes.BlockSize})
+				if err != nil {
+					return err
+				}
+				_, err = io.Copy(modes.NewCTRWriter(out, s), in)
+				return err
+			default:
+				return fmt.Errorf("-mode: unknown mode %q", *modeName)
+			}
+
+			w := modes.NewEncryptWriter(out, mode, scheme)
+			if decrypt {
+				w = modes.NewDecryptWriter(out, mode, scheme)
+			}
+			if _, err := io.Copy(w, in); err != nil {
+				return err
+			}
+			return w.Close()
+		}
+	}
+}
+
+// each runs f over every message, and writes out each result.
+func each(msgs [][]byte, out *output, f func([]byte) ([]byte, error)) error {
+	for _, m := range msgs {
+		res, err := f(m)
+		if err != nil {
+			return err
+		}
+		if err := out.data(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paddingFlag adds the flag for choosing a padding scheme.
+func paddingFlag(fs *flag.FlagSet) *string {
+	return fs.String("padding", "pkcs7", "the padding scheme: pkcs7, pkcs5, ansix923, iso10126, iso7816, zero or none")
+}
+
+func lookupPadding(name string) (padding.Scheme, error) {
+	scheme, ok := padding.ByName(name)
+	if !ok {
+		return nil, fmt.Errorf("-padding: unknown scheme %q", name)
+	}
+	return scheme, nil
+}
+
+// requiredHexFlag is hexFlag for flags which have to be given.
+func requiredHexFlag(name, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("-%s is required", name)
+	}
+	return hexFlag(name, value)
+}
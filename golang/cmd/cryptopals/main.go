@@ -0,0 +1,249 @@
+// Command cryptopals runs the analysis and attack code from the challenges
+// on your own data, without having to write any Go.
+//
+// Usage:
+//
+//	cryptopals <command> [flags] [file]
+//
+// Input is read from the named file, or standard input if there isn't one
+// or it's "-". Use -inform and -outform to pick hex, base64 or raw for the
+// data going in and coming out. Keys, IVs and nonces are given in hex.
+//
+// The encrypt and decrypt commands only take raw data, and work through it
+// a chunk at a time, so that they can deal with files too big to fit in
+// memory.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// errUsage is returned when the command line doesn't make sense. The flag
+// package has already said why by the time we see it.
+var errUsage = errors.New("usage")
+
+// command is a subcommand. flags registers any flags of its own, and
+// returns the function which does the work once they've been parsed.
+// Commands which can work on files too big to read into memory set stream
+// instead, which gets the raw input and output as they are.
+type command struct {
+	help    string
+	inform  string
+	outform string
+	lines   bool
+	flags   func(fs *flag.FlagSet) func(msgs [][]byte, out *output) error
+	stream  func(fs *flag.FlagSet) func(in io.Reader, out io.Writer) error
+}
+
+// output writes results to the chosen destination in the chosen encoding.
+// Notes about them, like which key was found, go to log instead, so that
+// the output stays clean enough to pipe into something else.
+type output struct {
+	w    io.Writer
+	form string
+	log  io.Writer
+}
+
+// data writes b in the output encoding.
+func (o *output) data(b []byte) error {
+	enc, err := encode(o.form, b)
+	if err != nil {
+		return err
+	}
+	_, err = o.w.Write(enc)
+	return err
+}
+
+// notef writes a line of text to the log.
+func (o *output) notef(format string, args ...interface{}) {
+	fmt.Fprintf(o.log, format+"\n", args...)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "cryptopals:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run is main, with everything it touches passed in so that it can be
+// tested.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return errUsage
+	}
+	name := args[0]
+	cmd, ok := commands[name]
+	if !ok {
+		usage(stderr)
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	inform := fs.String("inform", cmd.inform, "input encoding: hex, base64 or raw")
+	outform := fs.String("outform", cmd.outform, "output encoding: hex, base64 or raw")
+	outName := fs.String("out", "-", "output file, or - for standard output")
+	lines := fs.Bool("lines", cmd.lines, "treat each line of the input as a separate message")
+	var do func([][]byte, *output) error
+	var stream func(io.Reader, io.Writer) error
+	if cmd.stream != nil {
+		stream = cmd.stream(fs)
+	} else {
+		do = cmd.flags(fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: cryptopals %s [flags] [file]\n\n%s\n\n", name, cmd.help)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if _, err := encode(*outform, nil); err != nil {
+		return err
+	}
+	if stream != nil {
+		if *inform != "raw" || *outform != "raw" || *lines {
+			return fmt.Errorf("%s only works on raw input and output", name)
+		}
+		return runStream(stream, fs.Arg(0), *outName, stdin, stdout)
+	}
+
+	raw, err := readInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	chunks := [][]byte{raw}
+	if *lines {
+		chunks = nil
+		for _, line := range strings.Split(string(raw), "\n") {
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				chunks = append(chunks, []byte(line))
+			}
+		}
+	}
+	msgs := make([][]byte, len(chunks))
+	for i, c := range chunks {
+		if msgs[i], err = decode(*inform, c); err != nil {
+			return err
+		}
+	}
+
+	w, err := createOutput(*outName, stdout)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return do(msgs, &output{w: w, form: *outform, log: stderr})
+}
+
+// runStream runs a streaming command from the named input to the named
+// output, a chunk at a time.
+func runStream(stream func(io.Reader, io.Writer) error, inName, outName string, stdin io.Reader, stdout io.Writer) error {
+	in := stdin
+	if inName != "" && inName != "-" {
+		f, err := os.Open(inName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	w, err := createOutput(outName, stdout)
+	if err != nil {
+		return err
+	}
+	if err := stream(in, w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// createOutput opens the named output file, or stdout for "-".
+func createOutput(name string, stdout io.Writer) (io.WriteCloser, error) {
+	if name == "-" {
+		return nopCloser{stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: cryptopals <command> [flags] [file]")
+	fmt.Fprintln(w, "\ncommands:")
+	cmds := commands
+	var names []string
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-20s %s\n", name, cmds[name].help)
+	}
+	fmt.Fprintln(w, "\nRun cryptopals <command> -h for the flags each command takes.")
+}
+
+func readInput(name string, stdin io.Reader) ([]byte, error) {
+	if name == "" || name == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// decode turns input in the given encoding into bytes. Whitespace is
+// ignored in hex and base64, since the challenge files wrap their lines.
+func decode(form string, in []byte) ([]byte, error) {
+	switch form {
+	case "raw":
+		return in, nil
+	case "hex":
+		return hex.DecodeString(strings.Join(strings.Fields(string(in)), ""))
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(in)), ""))
+	}
+	return nil, fmt.Errorf("unknown encoding %q", form)
+}
+
+// encode turns bytes into output in the given encoding. Hex and base64
+// get a trailing newline, so they're easy to read in a terminal.
+func encode(form string, out []byte) ([]byte, error) {
+	switch form {
+	case "raw":
+		return out, nil
+	case "hex":
+		return []byte(hex.EncodeToString(out) + "\n"), nil
+	case "base64":
+		return []byte(base64.StdEncoding.EncodeToString(out) + "\n"), nil
+	}
+	return nil, fmt.Errorf("unknown encoding %q", form)
+}
+
+// hexFlag parses a flag given in hex.
+func hexFlag(name, value string) ([]byte, error) {
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("-%s: %v", name, err)
+	}
+	return b, nil
+}
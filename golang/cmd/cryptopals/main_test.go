@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+// yellowSubmarine is "YELLOW SUBMARINE" in hex.
+const yellowSubmarine = "59454c4c4f57205355424d4152494e45"
+
+func runCommand(t *testing.T, stdin string, args ...string) (stdout, stderr string) {
+	t.Helper()
+	var out, log bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out, &log)
+	if err != nil {
+		t.Fatalf("cryptopals %s: %v\n%s", strings.Join(args, " "), err, log.String())
+	}
+	return out.String(), log.String()
+}
+
+func TestHex2B64(t *testing.T) {
+	out, _ := runCommand(t, "49276d206b696c6c696e6720796f757220627261696e206c696b65206120706f69736f6e6f7573206d757368726f6f6d\n", "hex2b64")
+	testutil.AssertEqual(t, "SSdtIGtpbGxpbmcgeW91ciBicmFpbiBsaWtlIGEgcG9pc29ub3VzIG11c2hyb29t\n", out)
+}
+
+func TestXOR(t *testing.T) {
+	out, _ := runCommand(t, "1c0111001f010100061a024b53535009181c", "xor", "-inform", "hex", "-key", "686974207468652062756c6c277320657965")
+	testutil.AssertEqual(t, "746865206b696420646f6e277420706c6179\n", out)
+}
+
+func TestSingleXOR(t *testing.T) {
+	out, log := runCommand(t, "", "single-xor", "-lines", "../../../inputs/4.txt")
+	testutil.AssertEqual(t, "Now that the party is jumping\n", out)
+	testutil.AssertEqual(t, true, strings.HasPrefix(log, "line 171\nkey 0x35, "))
+}
+
+func TestBreakRepeatingXOR(t *testing.T) {
+	out, log := runCommand(t, "", "break-repeating-xor", "../../../inputs/6.txt")
+	testutil.AssertEqual(t, string(testutil.ReadFile(t, "../../../outputs/6.txt")), out)
+	testutil.AssertEqual(t, "key \"Terminator X: Bring the noise\"\n", log)
+}
+
+func TestDetectECB(t *testing.T) {
+	out, log := runCommand(t, "", "detect-ecb", "../../../inputs/8.txt")
+	testutil.AssertEqual(t, "line 133\n", log)
+	testutil.AssertEqual(t, true, strings.HasPrefix(out, "d880619740a8a19b7840a8a31c810a3d"))
+}
+
+func TestECBDecrypt(t *testing.T) {
+	out, _ := runCommand(t, "", "ecb-decrypt", "-key", yellowSubmarine, "../../../inputs/7.txt")
+	testutil.AssertEqual(t, string(testutil.ReadFile(t, "../../../outputs/6.txt")), out)
+}
+
+func TestCBCDecrypt(t *testing.T) {
+	out, _ := runCommand(t, "", "cbc-decrypt", "-key", yellowSubmarine, "../../../inputs/10.txt")
+	testutil.AssertEqual(t, string(testutil.ReadFile(t, "../../../outputs/6.txt")), out)
+
+	out, _ = runCommand(t, "", "cbc-decrypt", "-key", yellowSubmarine, "-padding", "none", "../../../inputs/10.txt")
+	testutil.AssertEqual(t, true, strings.HasSuffix(out, "\x04\x04\x04\x04"))
+}
+
+func TestCTR(t *testing.T) {
+	out, _ := runCommand(t, "L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==",
+		"ctr", "-inform", "base64", "-key", yellowSubmarine)
+	testutil.AssertEqual(t, "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby ", out)
+
+	// and back again
+	out, _ = runCommand(t, out, "ctr", "-outform", "base64", "-key", yellowSubmarine)
+	testutil.AssertEqual(t, "L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==\n", out)
+
+	// NIST SP 800-38A F.5.1
+	out, _ = runCommand(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51",
+		"ctr", "-inform", "hex", "-outform", "hex", "-key", "2b7e151628aed2a6abf7158809cf4f3c",
+		"-nonce", "f0f1f2f3f4f5f6f7", "-big-endian", "-initial", "0xf8f9fafbfcfdfeff")
+	testutil.AssertEqual(t, "874d6191b620e3261bef6864990db6ce9806f66b7970fdff8617187bb9fffdff\n", out)
+
+	var log bytes.Buffer
+	err := run([]string{"ctr", "-key", yellowSubmarine, "-bits", "8", "-initial", "255"}, strings.NewReader(strings.Repeat("A", 17)), &bytes.Buffer{}, &log)
+	testutil.AssertEqual(t, "modes: CTR counter wrapped around", err.Error())
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	plainText := string(testutil.ReadFile(t, "../../../outputs/6.txt"))
+	cipherText := string(testutil.DecodeBase64(t, strings.Join(strings.Fields(string(testutil.ReadFile(t, "../../../inputs/10.txt"))), "")))
+
+	out, _ := runCommand(t, plainText, "encrypt", "-key", yellowSubmarine)
+	testutil.AssertEqual(t, cipherText, out)
+	out, _ = runCommand(t, cipherText, "decrypt", "-key", yellowSubmarine)
+	testutil.AssertEqual(t, plainText, out)
+
+	for _, mode := range []string{"ecb", "ctr"} {
+		out, _ = runCommand(t, plainText, "encrypt", "-mode", mode, "-key", yellowSubmarine)
+		out, _ = runCommand(t, out, "decrypt", "-mode", mode, "-key", yellowSubmarine)
+		testutil.AssertEqual(t, plainText, out)
+	}
+
+	// the file arguments work too
+	name := t.TempDir() + "/plain"
+	runCommand(t, plainText, "encrypt", "-mode", "ctr", "-key", yellowSubmarine, "-out", name)
+	out, _ = runCommand(t, "", "decrypt", "-mode", "ctr", "-key", yellowSubmarine, name)
+	testutil.AssertEqual(t, plainText, out)
+
+	err := run([]string{"decrypt", "-key", yellowSubmarine}, strings.NewReader(cipherText[:40]), &bytes.Buffer{}, &bytes.Buffer{})
+	testutil.AssertEqual(t, "modes: input not full blocks", err.Error())
+	err = run([]string{"encrypt", "-key", yellowSubmarine, "-outform", "hex"}, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	testutil.AssertEqual(t, "encrypt only works on raw input and output", err.Error())
+}
+
+func TestPad(t *testing.T) {
+	out, _ := runCommand(t, "YELLOW SUBMARINE", "pad", "-size", "20")
+	testutil.AssertEqual(t, yellowSubmarine+"04040404\n", out)
+	out, _ = runCommand(t, "YELLOW SUBMARINE", "pad", "-size", "20", "-padding", "iso7816")
+	testutil.AssertEqual(t, yellowSubmarine+"80000000\n", out)
+}
+
+func TestUsage(t *testing.T) {
+	var out, log bytes.Buffer
+	testutil.AssertEqual(t, errUsage, run(nil, strings.NewReader(""), &out, &log))
+	testutil.AssertEqual(t, true, strings.Contains(log.String(), "break-repeating-xor"))
+	testutil.AssertEqual(t, errUsage, run([]string{"rot13"}, strings.NewReader(""), &out, &log))
+	testutil.AssertEqual(t, errUsage, run([]string{"xor", "-nope"}, strings.NewReader(""), &out, &log))
+
+	err := run([]string{"ecb-decrypt"}, strings.NewReader(""), &out, &log)
+	testutil.AssertEqual(t, "-key is required", err.Error())
+}
@@ -0,0 +1,17 @@
+// Package codec converts between the encodings the challenges use.
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// Hex2Base64 converts a hex encoded string to standard base64.
+func Hex2Base64(hexBytes string) (string, error) {
+	data, err := hex.DecodeString(hexBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,13 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge1(t *testing.T) {
+	s, err := Hex2Base64("49276d206b696c6c696e6720796f757220627261696e206c696b65206120706f69736f6e6f7573206d757368726f6f6d")
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, "SSdtIGtpbGxpbmcgeW91ciBicmFpbiBsaWtlIGEgcG9pc29ub3VzIG11c2hyb29t", s)
+}
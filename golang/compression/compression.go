@@ -0,0 +1,138 @@
+// Package compression recovers a secret from a request by watching how well
+// it compresses, in the style of the CRIME attack.
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"fmt"
+	"io"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+// formatSessionRequest returns the HTTP request a client holding sessionID
+// would make to POST body.
+func formatSessionRequest(sessionID, body []byte) []byte {
+	return []byte(fmt.Sprintf("POST / HTTP/1.1\n"+
+		"Host: hapless.com\n"+
+		"Cookie: sessionid=%s\n"+
+		"Content-Length: %d\n"+
+		"%s", sessionID, len(body), body))
+}
+
+// NewOracle returns an oracle which compresses a request carrying
+// sessionID and the given body, encrypts it under a fresh key using mode,
+// and only lets you see how long the result is. The keys and IVs are read
+// from rand, or crypto/rand if it's nil.
+func NewOracle(rand io.Reader, sessionID []byte, mode modes.Mode) func([]byte) int {
+	src := random.New(rand)
+
+	// The faster compression levels don't look very hard for matches in
+	// short inputs, which would hide the very thing we're measuring.
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+
+	return func(body []byte) int {
+		buf.Reset()
+		w.Reset(&buf)
+		w.Write(formatSessionRequest(sessionID, body))
+		w.Close()
+
+		b, _ := aes.NewCipher(src.Key())
+		switch mode {
+		case modes.ModeCTR:
+			nonce := make([]byte, 8)
+			src.Fill(nonce)
+			c, err := modes.NewCTRCipher(b, nonce)
+			if err != nil {
+				panic(err)
+			}
+			out, _ := c.Seal(buf.Bytes())
+			return len(out)
+		case modes.ModeCBC:
+			out, err := modes.NewCBCCipher(b, src.IV(), padding.PKCS7).Seal(buf.Bytes())
+			if err != nil {
+				panic(err)
+			}
+			return len(out)
+		default:
+			panic("unsupported mode " + mode.String())
+		}
+	}
+}
+
+// sessionIDAlphabet is what a base64 encoded session ID is made of. The
+// newline is how we find out that we have reached the end of it.
+const sessionIDAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=\n"
+
+// compressionJunk are bytes which don't occur in a request or a session ID,
+// and so can't be compressed away. Prepending them to a body grows the
+// compressed request a byte or so at a time.
+const compressionJunk = "!\"#$%&'()*,-.;<>?@[\\]^_`{|}~"
+
+// compressedSize estimates how well the request with body compresses. With
+// CBC the oracle only reveals whole blocks, so we also count how much junk we
+// can add before the ciphertext grows, which gives a finer measure. The same
+// code copes with stream ciphers, where a byte of junk is usually enough.
+func compressedSize(oracle func([]byte) int, body []byte) int {
+	base := oracle(body)
+	for n := 1; n <= len(compressionJunk); n++ {
+		if oracle(append([]byte(compressionJunk[:n]), body...)) > base {
+			return base - n
+		}
+	}
+	panic("compressedSize: ran out of junk")
+}
+
+// maxCandidates is how many equally good guesses DiscoverSessionID will
+// carry along at once before it gives up.
+const maxCandidates = 64
+
+// DiscoverSessionID recovers the session ID from a compression oracle by
+// guessing the next character and seeing which guess compresses best, since
+// a correct guess extends a match with the real cookie. It stops at the
+// newline which ends the cookie. It returns nil if the guesses stop
+// compressing any better than the rest, or never narrow down to one, which
+// can happen when padding hides the difference between them.
+func DiscoverSessionID(oracle func([]byte) int) []byte {
+	// Several guesses can compress equally well, so we keep all of the
+	// best candidates and extend each of them.
+	candidates := [][]byte{[]byte("sessionid=")}
+
+	for {
+		var best [][]byte
+		bestSize, worstSize := 0, 0
+
+		for _, c := range candidates {
+			for _, guess := range []byte(sessionIDAlphabet) {
+				attempt := append(append([]byte{}, c...), guess)
+				size := compressedSize(oracle, attempt)
+				switch {
+				case len(best) == 0 || size < bestSize:
+					best = [][]byte{attempt}
+					bestSize = size
+				case size == bestSize:
+					best = append(best, attempt)
+				}
+				worstSize = max(worstSize, size)
+			}
+		}
+
+		// At most one guess per candidate is right, so if none of them
+		// stands out, there's nothing left to match.
+		if bestSize == worstSize {
+			return nil
+		}
+		if len(best) == 1 && best[0][len(best[0])-1] == '\n' {
+			return best[0][len("sessionid=") : len(best[0])-1]
+		}
+		if len(best) > maxCandidates {
+			return nil
+		}
+		candidates = best
+	}
+}
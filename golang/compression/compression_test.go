@@ -0,0 +1,23 @@
+package compression
+
+import (
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+)
+
+func TestChallenge51(t *testing.T) {
+	sessionID := []byte("TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE=")
+
+	for _, mode := range []modes.Mode{modes.ModeCTR, modes.ModeCBC} {
+		t.Run(mode.String(), func(t *testing.T) {
+			oracle := NewOracle(detrand.New(51), sessionID, mode)
+			testutil.AssertEqual(t, string(sessionID), string(DiscoverSessionID(oracle)))
+		})
+	}
+
+	// an oracle which gives nothing away makes it give up rather than loop
+	testutil.AssertEqual(t, 0, len(DiscoverSessionID(func(body []byte) int { return len(body) })))
+}
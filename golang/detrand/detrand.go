@@ -0,0 +1,41 @@
+// Package detrand is a deterministic stand-in for crypto/rand. Everything
+// which makes keys, oracles or attacks takes an io.Reader for its
+// randomness, and given a Reader from here with the same seed, it does
+// exactly the same thing again. That makes tests repeatable, and a failing
+// attack run can be replayed from its seed.
+//
+// Never use it for anything which needs to be secret.
+package detrand
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Reader is an endless stream of random looking bytes, which only depends
+// on the seed it was made with. It's AES-CTR, keyed with a hash of the
+// seed, encrypting zeros.
+type Reader struct {
+	stream cipher.Stream
+}
+
+// New returns a Reader for the given seed.
+func New(seed int64) *Reader {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	key := sha256.Sum256(buf[:])
+	b, err := aes.NewCipher(key[:16])
+	if err != nil {
+		panic(err)
+	}
+	return &Reader{stream: cipher.NewCTR(b, make([]byte, aes.BlockSize))}
+}
+
+// Read fills p with the next len(p) bytes of the stream. It never fails.
+func (r *Reader) Read(p []byte) (int, error) {
+	clear(p)
+	r.stream.XORKeyStream(p, p)
+	return len(p), nil
+}
@@ -0,0 +1,53 @@
+package detrand
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestReader(t *testing.T) {
+	read := func(r io.Reader, n int) []byte {
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		testutil.FatalIfErr(t, err)
+		return buf
+	}
+
+	// the same seed gives the same bytes, however they're read
+	a, b := New(42), New(42)
+	whole := read(a, 100)
+	testutil.AssertEqual(t, whole, append(read(b, 7), read(b, 93)...))
+
+	// but another seed doesn't
+	testutil.AssertEqual(t, false, bytes.Equal(whole, read(New(43), 100)))
+
+	// and this one is the same everywhere, forever
+	testutil.AssertEqual(t, testutil.DecodeHex(t, "68b52a4cc88be618de6b4bd5cdc2afb9"), read(New(1), 16))
+}
+
+func TestSource(t *testing.T) {
+	// a Source reading from a Reader does the same thing every time
+	a, b := random.New(New(7)), random.New(New(7))
+	testutil.AssertEqual(t, a.Key(), b.Key())
+	testutil.AssertEqual(t, a.Bytes(20), b.Bytes(20))
+	testutil.AssertEqual(t, a.Int(1000), b.Int(1000))
+	n := new(big.Int).Lsh(big.NewInt(1), 200)
+	testutil.AssertEqual(t, a.BigInt(n).String(), b.BigInt(n).String())
+
+	for _, bits := range []int{3, 17, 256} {
+		p := a.Prime(bits)
+		testutil.AssertEqual(t, bits, p.BitLen())
+		testutil.AssertEqual(t, true, p.ProbablyPrime(20))
+		testutil.AssertEqual(t, p.String(), b.Prime(bits).String())
+	}
+
+	// and so do the Sources split from it
+	sa, sb := a.Split(), b.Split()
+	testutil.AssertEqual(t, sa.Key(), sb.Key())
+	testutil.AssertEqual(t, false, bytes.Equal(a.Key(), sa.Key()))
+}
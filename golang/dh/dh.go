@@ -0,0 +1,171 @@
+// Package dh does Diffie-Hellman in subgroups of the integers modulo a
+// prime, and attacks it when the other side trusts whatever public key it
+// is sent.
+package dh
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/dlog"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/bigint"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Group is a Diffie-Hellman group: the subgroup of order q generated by g
+// modulo the prime p.
+type Group struct {
+	P, G, Q *big.Int
+}
+
+// NewGroup parses the decimal parameters of a group.
+func NewGroup(p, g, q string) *Group {
+	return &Group{P: bigint.MustParse(p), G: bigint.MustParse(g), Q: bigint.MustParse(q)}
+}
+
+// GenerateKey returns a private key in [1, q) and the matching public key,
+// reading the randomness from rand, or crypto/rand if it's nil.
+func (grp *Group) GenerateKey(rand io.Reader) (priv, pub *big.Int) {
+	priv = random.New(rand).BigInt(new(big.Int).Sub(grp.Q, big.NewInt(1)))
+	priv.Add(priv, big.NewInt(1))
+	return priv, new(big.Int).Exp(grp.G, priv, grp.P)
+}
+
+// SharedSecret combines our private key with someone else's public key.
+func (grp *Group) SharedSecret(priv, pub *big.Int) *big.Int {
+	return new(big.Int).Exp(pub, priv, grp.P)
+}
+
+// MAC authenticates msg with a key derived from a shared secret.
+func MAC(secret *big.Int, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret.Bytes())
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+// Challenge57Group is a Diffie-Hellman group where p-1 has plenty of small
+// factors besides q.
+var Challenge57Group = NewGroup(
+	"7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771",
+	"4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143",
+	"236234353446506858198510045061214171961",
+)
+
+// Bob does Diffie-Hellman with anyone who asks, and replies with a message
+// authenticated using the shared secret. He never checks that the public
+// key he's sent is in the subgroup generated by g.
+type Bob struct {
+	grp  *Group
+	priv *big.Int
+	Pub  *big.Int
+}
+
+// NewBob returns a Bob with a fresh key pair in grp, generated from rand.
+func NewBob(rand io.Reader, grp *Group) *Bob {
+	priv, pub := grp.GenerateKey(rand)
+	return &Bob{grp: grp, priv: priv, Pub: pub}
+}
+
+// Respond returns a message and its MAC under the secret Bob shares with
+// whoever owns pub.
+func (b *Bob) Respond(pub *big.Int) (msg, mac []byte) {
+	msg = []byte("crazy flamboyant for the rap enjoyment")
+	return msg, MAC(b.grp.SharedSecret(b.priv, pub), msg)
+}
+
+// Cofactor returns j = (p-1)/q, the part of the order of the whole group
+// which the subgroup we're meant to use leaves behind.
+func (grp *Group) Cofactor() *big.Int {
+	j := new(big.Int).Sub(grp.P, big.NewInt(1))
+	return j.Div(j, grp.Q)
+}
+
+// ElementOfOrder returns a random element of order r modulo p, where r is a
+// prime factor of p-1, made from rand, or crypto/rand if it's nil.
+func ElementOfOrder(rand io.Reader, p, r *big.Int) *big.Int {
+	src := random.New(rand)
+	exp := new(big.Int).Sub(p, big.NewInt(1))
+	exp.Div(exp, r)
+	for {
+		h := src.BigInt(p)
+		h.Exp(h, exp, p)
+		if h.Cmp(big.NewInt(1)) != 0 {
+			return h
+		}
+	}
+}
+
+// bruteForceMAC finds k in [0, r) such that mac is the MAC of msg under
+// the shared secret h^k mod p.
+func bruteForceMAC(p, h, r *big.Int, msg, mac []byte) *big.Int {
+	secret := big.NewInt(1)
+	for k := big.NewInt(0); k.Cmp(r) < 0; k.Add(k, big.NewInt(1)) {
+		if hmac.Equal(mac, MAC(secret, msg)) {
+			return k
+		}
+		secret.Mul(secret, h)
+		secret.Mod(secret, p)
+	}
+	panic(fmt.Sprintf("bruteForceMAC: no match for order %v", r))
+}
+
+// SubgroupConfinement recovers Bob's private key x modulo the product of
+// factors, each of which is a prime factor of p-1. For each factor r, we send
+// Bob an element h of order r instead of a proper public key. The secret he
+// then shares with us is h^x, which is one of only r values, so we can
+// brute force his MAC to find x mod r. We stop once the product is bigger
+// than q, since that's enough to pin down x completely. The elements are
+// made from rand, or crypto/rand if it's nil.
+func SubgroupConfinement(rand io.Reader, bob *Bob, factors []*big.Int) (x, r *big.Int) {
+	var residues, moduli []*big.Int
+	product := big.NewInt(1)
+
+	for _, f := range factors {
+		if product.Cmp(bob.grp.Q) > 0 {
+			break
+		}
+		h := ElementOfOrder(rand, bob.grp.P, f)
+		msg, mac := bob.Respond(h)
+		residues = append(residues, bruteForceMAC(bob.grp.P, h, f, msg, mac))
+		moduli = append(moduli, f)
+		product.Mul(product, f)
+	}
+
+	return dlog.CRT(residues, moduli)
+}
+
+// Challenge58Group is a Diffie-Hellman group where the small factors of p-1
+// don't multiply up to anything like q.
+var Challenge58Group = NewGroup(
+	"11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623",
+	"622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357",
+	"335062023296420808191071248367701059461",
+)
+
+// RecoverKey recovers Bob's private key, using the subgroup confinement
+// attack to learn x mod r, and Pollard's kangaroo for the rest. Writing
+// x = n + m*r, we know n, so
+//
+// y * g^-n = g^(m*r) = (g^r)^m
+//
+// and m is somewhere in [0, (q-1)/r], which is a much smaller interval to
+// search than [0, q). Both parts read what randomness they need from rand,
+// or crypto/rand if it's nil.
+func RecoverKey(rand io.Reader, bob *Bob, factors []*big.Int) *big.Int {
+	grp := bob.grp
+	n, r := SubgroupConfinement(rand, bob, factors)
+	if r.Cmp(grp.Q) > 0 {
+		return n
+	}
+
+	zp := dlog.ZpGroup{P: grp.P}
+	g := zp.Exp(grp.G, r)
+	y := zp.Op(bob.Pub, zp.Exp(grp.G, new(big.Int).Neg(n)))
+	b := new(big.Int).Sub(grp.Q, big.NewInt(1))
+	m := dlog.KangarooLog(rand, zp, g, y, big.NewInt(0), b.Div(b, r))
+
+	return n.Add(n, m.Mul(m, r))
+}
@@ -0,0 +1,29 @@
+package dh
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/dlog"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge57(t *testing.T) {
+	grp := Challenge57Group
+	testutil.AssertEqual(t, 0, new(big.Int).Exp(grp.G, grp.Q, grp.P).Cmp(big.NewInt(1)))
+
+	rand := detrand.New(57)
+	bob := NewBob(rand, grp)
+	x, r := SubgroupConfinement(rand, bob, dlog.SmallFactors(grp.Cofactor(), 1<<16))
+	testutil.AssertEqual(t, 1, r.Cmp(grp.Q))
+	testutil.AssertEqual(t, bob.priv.String(), x.String())
+}
+
+func TestChallenge58(t *testing.T) {
+	grp := Challenge58Group
+	rand := detrand.New(58)
+	bob := NewBob(rand, grp)
+	x := RecoverKey(rand, bob, dlog.SmallFactors(grp.Cofactor(), 1<<16))
+	testutil.AssertEqual(t, bob.priv.String(), x.String())
+}
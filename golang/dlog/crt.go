@@ -0,0 +1,44 @@
+package dlog
+
+import "math/big"
+
+// SmallFactors returns the distinct prime factors of n which are less than
+// limit, found by trial division.
+func SmallFactors(n *big.Int, limit int64) []*big.Int {
+	var res []*big.Int
+	n = new(big.Int).Set(n)
+	d, q, r := new(big.Int), new(big.Int), new(big.Int)
+
+	for i := int64(2); i < limit; i++ {
+		d.SetInt64(i)
+		q.QuoRem(n, d, r)
+		if r.Sign() != 0 {
+			continue
+		}
+		res = append(res, big.NewInt(i))
+		// divide out every power of i, so that no composite number
+		// divides whatever is left
+		for r.Sign() == 0 {
+			n.Set(q)
+			q.QuoRem(n, d, r)
+		}
+	}
+	return res
+}
+
+// CRT uses the Chinese Remainder Theorem to find x mod m, where m is the
+// product of moduli, from x mod each of the moduli. The moduli need to be
+// pairwise coprime.
+func CRT(residues, moduli []*big.Int) (x, m *big.Int) {
+	x, m = big.NewInt(0), big.NewInt(1)
+	for i, r := range moduli {
+		// We want x + m*t = residues[i] (mod r), so
+		// t = (residues[i] - x) * m^-1 (mod r)
+		t := new(big.Int).Sub(residues[i], x)
+		t.Mul(t, new(big.Int).ModInverse(m, r))
+		t.Mod(t, r)
+		x.Add(x, t.Mul(t, m))
+		m.Mul(m, r)
+	}
+	return x, m
+}
@@ -0,0 +1,78 @@
+package dlog
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/bigint"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestSmallFactors(t *testing.T) {
+	testutil.AssertEqual(t, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(7)}, SmallFactors(big.NewInt(2*2*3*7*7*101), 100))
+	testutil.AssertEqual(t, []*big.Int(nil), SmallFactors(big.NewInt(101), 100))
+}
+
+func TestCRT(t *testing.T) {
+	x, m := CRT(
+		[]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)},
+		[]*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)})
+	testutil.AssertEqual(t, "23", x.String())
+	testutil.AssertEqual(t, "105", m.String())
+}
+
+func TestPollardKangaroo(t *testing.T) {
+	// the group from challenge 58
+	p := bigint.MustParse("11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623")
+	g := bigint.MustParse("622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357")
+	zp := ZpGroup{p}
+
+	for _, bits := range []uint{20, 32} {
+		b := new(big.Int).Lsh(big.NewInt(1), bits)
+		rand := detrand.New(int64(bits))
+		x := random.New(rand).BigInt(b)
+		y := zp.Exp(g, x)
+		testutil.AssertEqual(t, x.String(), KangarooLog(rand, zp, g, y, big.NewInt(0), b).String())
+	}
+
+	// nothing to find outside the interval
+	y := zp.Exp(g, big.NewInt(1<<40))
+	_, ok := PollardKangaroo(zp, g, y, big.NewInt(0), big.NewInt(1<<20), nil)
+	testutil.AssertEqual(t, false, ok)
+
+	// an interval too wide to ever walk still gets a tame jump count
+	testutil.AssertEqual(t, int64(math.MaxInt64), NewKangarooParams(new(big.Int).Lsh(big.NewInt(1), 200)).TameJumps)
+
+	// the parameters are tunable
+	params := &KangarooParams{
+		Jumps:     []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(7), big.NewInt(15)},
+		TameJumps: 100,
+	}
+	testutil.AssertEqual(t, "6", params.MeanJump().String())
+	// The wild kangaroo can miss the trap, so if it does, move y and the
+	// interval along a little and try again, as KangarooLog would.
+	y = zp.Exp(g, big.NewInt(1234))
+	for shift := int64(0); shift < 10; shift++ {
+		s := big.NewInt(shift)
+		x, ok := PollardKangaroo(zp, g, zp.Op(y, zp.Exp(g, s)), big.NewInt(1000+shift), big.NewInt(2000+shift), params)
+		if ok {
+			testutil.AssertEqual(t, "1234", x.Sub(x, s).String())
+			return
+		}
+	}
+	t.Error("the kangaroo never found 1234")
+}
+
+func TestPohligHellman(t *testing.T) {
+	// 2 generates the whole group modulo 1019, and 1018 = 2 * 509
+	zp := ZpGroup{big.NewInt(1019)}
+	factors := []*big.Int{big.NewInt(2), big.NewInt(509)}
+	for _, x := range []int64{0, 1, 2, 777, 1017} {
+		got, ok := PohligHellman(zp, big.NewInt(2), zp.Exp(big.NewInt(2), big.NewInt(x)), factors)
+		testutil.AssertEqual(t, true, ok)
+		testutil.AssertEqual(t, big.NewInt(x).String(), got.String())
+	}
+}
@@ -0,0 +1,205 @@
+// Package dlog finds discrete logarithms in any group with cheap enough
+// arithmetic, along with the number theory the attacks built on them need.
+package dlog
+
+import (
+	"io"
+	"math"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Group is the arithmetic which generic discrete log algorithms need from a
+// cyclic group with elements of type E.
+type Group[E any] interface {
+	// Op is the group operation, whether that's written as
+	// multiplication or addition.
+	Op(x, y E) E
+	// Exp applies the group operation to x, k times.
+	Exp(x E, k *big.Int) E
+	Equal(x, y E) bool
+	// Hash maps an element to a number, which needs to be cheap to compute
+	// and well distributed, but nothing more.
+	Hash(x E) uint64
+}
+
+// ZpGroup is the multiplicative group of integers modulo a prime p.
+type ZpGroup struct {
+	P *big.Int
+}
+
+func (z ZpGroup) Op(x, y *big.Int) *big.Int {
+	res := new(big.Int).Mul(x, y)
+	return res.Mod(res, z.P)
+}
+
+func (z ZpGroup) Exp(x, k *big.Int) *big.Int {
+	return new(big.Int).Exp(x, k, z.P)
+}
+
+func (z ZpGroup) Equal(x, y *big.Int) bool {
+	return x.Cmp(y) == 0
+}
+
+func (z ZpGroup) Hash(x *big.Int) uint64 {
+	return x.Uint64()
+}
+
+// KangarooParams tune Pollard's kangaroo algorithm. NewKangarooParams
+// picks reasonable ones for an interval, but they can be set by hand too.
+type KangarooParams struct {
+	// Jumps is the jump function: a kangaroo at y jumps forward by
+	// Jumps[hash(y) % len(Jumps)]. Their average is the mean step, which
+	// should be around half the square root of the width of the interval.
+	Jumps []*big.Int
+
+	// TameJumps is how far the tame kangaroo goes before it sets its
+	// trap, which should be a small multiple of the mean jump.
+	TameJumps int64
+}
+
+// NewKangarooParams returns parameters for searching an interval of the
+// given width. The jumps are powers of two, chosen so that the mean jump is
+// around sqrt(width)/2, and the tame kangaroo makes four times that many
+// jumps, or as many as an int64 can count for intervals too wide for that.
+func NewKangarooParams(width *big.Int) *KangarooParams {
+	target := new(big.Int).Sqrt(width)
+	target.Rsh(target, 1)
+
+	params := &KangarooParams{}
+	for k := 1; ; k++ {
+		params.Jumps = append(params.Jumps, new(big.Int).Lsh(big.NewInt(1), uint(k-1)))
+		if params.MeanJump().Cmp(target) >= 0 {
+			break
+		}
+	}
+	tame := params.MeanJump()
+	tame.Lsh(tame, 2)
+	switch {
+	case !tame.IsInt64():
+		params.TameJumps = math.MaxInt64
+	case tame.Sign() == 0:
+		params.TameJumps = 1
+	default:
+		params.TameJumps = tame.Int64()
+	}
+	return params
+}
+
+// MeanJump returns the average distance a kangaroo jumps, rounded down.
+func (params *KangarooParams) MeanJump() *big.Int {
+	sum := big.NewInt(0)
+	for _, j := range params.Jumps {
+		sum.Add(sum, j)
+	}
+	return sum.Div(sum, big.NewInt(int64(len(params.Jumps))))
+}
+
+// PollardKangaroo looks for x in [a, b] such that y = g^x, in around
+// sqrt(b-a) group operations. A tame kangaroo starts at g^b, hops along a
+// pseudo-random path and leaves a trap where it stops. A wild kangaroo
+// starting at y then follows the same rules, and if its path ever meets the
+// tame one, it'll follow it into the trap, and we can work out x from the
+// distances they covered. The wild kangaroo can miss the path though, so
+// this fails now and again even when x is in the interval. If params is
+// nil, NewKangarooParams picks some for us.
+func PollardKangaroo[E any](grp Group[E], g, y E, a, b *big.Int, params *KangarooParams) (*big.Int, bool) {
+	if params == nil {
+		params = NewKangarooParams(new(big.Int).Sub(b, a))
+	}
+
+	steps := make([]E, len(params.Jumps))
+	for i, j := range params.Jumps {
+		steps[i] = grp.Exp(g, j)
+	}
+	jump := func(pos E) int {
+		return int(grp.Hash(pos) % uint64(len(params.Jumps)))
+	}
+
+	// the tame kangaroo
+	xT := big.NewInt(0)
+	yT := grp.Exp(g, b)
+	for i := int64(0); i < params.TameJumps; i++ {
+		j := jump(yT)
+		xT.Add(xT, params.Jumps[j])
+		yT = grp.Op(yT, steps[j])
+	}
+
+	// the wild kangaroo gives up once it has gone past the trap
+	limit := new(big.Int).Sub(b, a)
+	limit.Add(limit, xT)
+	xW := big.NewInt(0)
+	yW := y
+	for xW.Cmp(limit) <= 0 {
+		if grp.Equal(yW, yT) {
+			// b + xT = x + xW
+			x := new(big.Int).Add(b, xT)
+			return x.Sub(x, xW), true
+		}
+		j := jump(yW)
+		xW.Add(xW, params.Jumps[j])
+		yW = grp.Op(yW, steps[j])
+	}
+
+	return nil, false
+}
+
+// KangarooLog finds x in [a, b] such that y = g^x, for when we know there
+// is one. Each time PollardKangaroo misses, we move y along by a random
+// known amount to send the wild kangaroo down a different path. Those
+// amounts are read from rand, or crypto/rand if it's nil.
+func KangarooLog[E any](rand io.Reader, grp Group[E], g, y E, a, b *big.Int) *big.Int {
+	_, x := KangarooLogOneOf(rand, grp, g, []E{y}, a, b)
+	return x
+}
+
+// KangarooLogOneOf is like KangarooLog, but for when we only know that one
+// of ys has a logarithm in [a, b]. It returns the index of that one along
+// with the logarithm.
+func KangarooLogOneOf[E any](rand io.Reader, grp Group[E], g E, ys []E, a, b *big.Int) (int, *big.Int) {
+	src := random.New(rand)
+	width := new(big.Int).Sub(b, a)
+	shift := big.NewInt(0)
+	for {
+		for i, y := range ys {
+			x, ok := PollardKangaroo(grp, g, grp.Op(y, grp.Exp(g, shift)),
+				new(big.Int).Add(a, shift), new(big.Int).Add(b, shift), nil)
+			if ok {
+				return i, x.Sub(x, shift)
+			}
+		}
+		shift = src.BigInt(width)
+	}
+}
+
+// PohligHellman finds x such that y = g^x, where the order of g is the
+// product of factors, which must be distinct small primes. For each factor
+// r, raising both g and y to the power order/r leaves a discrete log modulo
+// r, small enough to find by brute force, and the Chinese remainder theorem
+// puts them back together. It returns false if y isn't a power of g.
+func PohligHellman[E any](grp Group[E], g, y E, factors []*big.Int) (*big.Int, bool) {
+	order := big.NewInt(1)
+	for _, r := range factors {
+		order.Mul(order, r)
+	}
+
+	residues := make([]*big.Int, len(factors))
+	for i, r := range factors {
+		e := new(big.Int).Div(order, r)
+		gr, yr := grp.Exp(g, e), grp.Exp(y, e)
+		cur := grp.Exp(g, big.NewInt(0))
+		for x := big.NewInt(0); ; x.Add(x, big.NewInt(1)) {
+			if x.Cmp(r) >= 0 {
+				return nil, false
+			}
+			if grp.Equal(cur, yr) {
+				residues[i] = x
+				break
+			}
+			cur = grp.Op(cur, gr)
+		}
+	}
+	x, _ := CRT(residues, factors)
+	return x, true
+}
@@ -0,0 +1,314 @@
+// Package ec does elliptic curve Diffie-Hellman and ECDSA over short
+// Weierstrass and Montgomery curves, and attacks them when the other side
+// doesn't check the points it's sent or the nonces it signs with.
+package ec
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// WeierstrassCurve is the short Weierstrass curve y^2 = x^3 + ax + b over
+// the integers modulo the prime p.
+type WeierstrassCurve struct {
+	P, A, B *big.Int
+}
+
+// Point is a point on a curve in affine coordinates. The point at
+// infinity, which is the identity, has nil coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+// Infinity is the point at infinity.
+var Infinity = Point{}
+
+// IsInfinity says whether pt is the point at infinity.
+func (pt Point) IsInfinity() bool {
+	return pt.X == nil
+}
+
+// Equal says whether pt and q are the same point.
+func (pt Point) Equal(q Point) bool {
+	if pt.IsInfinity() || q.IsInfinity() {
+		return pt.IsInfinity() == q.IsInfinity()
+	}
+	return pt.X.Cmp(q.X) == 0 && pt.Y.Cmp(q.Y) == 0
+}
+
+// Bytes encodes the point, for when it's used as a shared secret.
+func (pt Point) Bytes() []byte {
+	if pt.IsInfinity() {
+		return nil
+	}
+	return append(pt.X.Bytes(), pt.Y.Bytes()...)
+}
+
+// mod reduces x modulo p, into [0, p).
+func (c *WeierstrassCurve) mod(x *big.Int) *big.Int {
+	return x.Mod(x, c.P)
+}
+
+// rhs returns x^3 + ax + b, which is y^2 for points on the curve.
+func (c *WeierstrassCurve) rhs(x *big.Int) *big.Int {
+	res := new(big.Int).Mul(x, x)
+	res.Add(res, c.A)
+	res.Mul(res, x)
+	res.Add(res, c.B)
+	return c.mod(res)
+}
+
+// IsOnCurve says whether pt satisfies the curve equation.
+func (c *WeierstrassCurve) IsOnCurve(pt Point) bool {
+	if pt.IsInfinity() {
+		return true
+	}
+	y2 := new(big.Int).Mul(pt.Y, pt.Y)
+	return c.mod(y2).Cmp(c.rhs(pt.X)) == 0
+}
+
+// Neg returns -pt, the reflection of pt in the x axis.
+func (c *WeierstrassCurve) Neg(pt Point) Point {
+	if pt.IsInfinity() {
+		return pt
+	}
+	return Point{new(big.Int).Set(pt.X), c.mod(new(big.Int).Neg(pt.Y))}
+}
+
+// Add adds two points using the affine formulas, which need an inversion
+// modulo p every time. Note that b doesn't appear anywhere, so nothing here
+// notices if the points are on some other curve.
+func (c *WeierstrassCurve) Add(p1, p2 Point) Point {
+	if p1.IsInfinity() {
+		return p2
+	}
+	if p2.IsInfinity() {
+		return p1
+	}
+	if p1.X.Cmp(p2.X) == 0 && c.mod(new(big.Int).Add(p1.Y, p2.Y)).Sign() == 0 {
+		return Infinity
+	}
+
+	var m *big.Int
+	if p1.X.Cmp(p2.X) == 0 {
+		// tangent: m = (3x^2 + a) / 2y
+		m = new(big.Int).Mul(p1.X, p1.X)
+		m.Mul(m, big.NewInt(3))
+		m.Add(m, c.A)
+		m.Mul(m, new(big.Int).ModInverse(new(big.Int).Lsh(p1.Y, 1), c.P))
+	} else {
+		// chord: m = (y2 - y1) / (x2 - x1)
+		m = new(big.Int).Sub(p2.Y, p1.Y)
+		m.Mul(m, new(big.Int).ModInverse(c.mod(new(big.Int).Sub(p2.X, p1.X)), c.P))
+	}
+	c.mod(m)
+
+	x := new(big.Int).Mul(m, m)
+	x.Sub(x, p1.X)
+	x.Sub(x, p2.X)
+	c.mod(x)
+
+	y := new(big.Int).Sub(p1.X, x)
+	y.Mul(y, m)
+	y.Sub(y, p1.Y)
+	c.mod(y)
+
+	return Point{x, y}
+}
+
+// scalarMultAffine computes k*pt by double-and-add with affine arithmetic.
+func (c *WeierstrassCurve) scalarMultAffine(pt Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.scalarMultAffine(c.Neg(pt), new(big.Int).Neg(k))
+	}
+	res := Infinity
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		res = c.Add(res, res)
+		if k.Bit(i) == 1 {
+			res = c.Add(res, pt)
+		}
+	}
+	return res
+}
+
+// jacobianPoint is a point in Jacobian coordinates, representing the affine
+// point (x/z^2, y/z^3). Working with these saves an inversion in every
+// addition, and only needs one at the end to get back to affine
+// coordinates. The point at infinity has z = 0.
+type jacobianPoint struct {
+	X, Y, Z *big.Int
+}
+
+func (c *WeierstrassCurve) toJacobian(pt Point) jacobianPoint {
+	if pt.IsInfinity() {
+		return jacobianPoint{big.NewInt(1), big.NewInt(1), big.NewInt(0)}
+	}
+	return jacobianPoint{new(big.Int).Set(pt.X), new(big.Int).Set(pt.Y), big.NewInt(1)}
+}
+
+func (c *WeierstrassCurve) fromJacobian(pt jacobianPoint) Point {
+	if pt.Z.Sign() == 0 {
+		return Infinity
+	}
+	zInv := new(big.Int).ModInverse(pt.Z, c.P)
+	zInv2 := c.mod(new(big.Int).Mul(zInv, zInv))
+	x := c.mod(new(big.Int).Mul(pt.X, zInv2))
+	y := c.mod(new(big.Int).Mul(pt.Y, zInv2.Mul(zInv2, zInv)))
+	return Point{x, y}
+}
+
+// jacobianDouble doubles a point in Jacobian coordinates.
+func (c *WeierstrassCurve) jacobianDouble(pt jacobianPoint) jacobianPoint {
+	if pt.Z.Sign() == 0 || pt.Y.Sign() == 0 {
+		return jacobianPoint{big.NewInt(1), big.NewInt(1), big.NewInt(0)}
+	}
+
+	// s = 4xy^2
+	y2 := c.mod(new(big.Int).Mul(pt.Y, pt.Y))
+	s := new(big.Int).Mul(pt.X, y2)
+	c.mod(s.Lsh(s, 2))
+
+	// m = 3x^2 + az^4
+	z2 := c.mod(new(big.Int).Mul(pt.Z, pt.Z))
+	m := new(big.Int).Mul(z2, z2)
+	m.Mul(m, c.A)
+	x2 := new(big.Int).Mul(pt.X, pt.X)
+	m.Add(m, x2.Mul(x2, big.NewInt(3)))
+	c.mod(m)
+
+	// x' = m^2 - 2s
+	x := new(big.Int).Mul(m, m)
+	x.Sub(x, new(big.Int).Lsh(s, 1))
+	c.mod(x)
+
+	// y' = m(s - x') - 8y^4
+	y := new(big.Int).Sub(s, x)
+	y.Mul(y, m)
+	y4 := new(big.Int).Mul(y2, y2)
+	y.Sub(y, y4.Lsh(y4, 3))
+	c.mod(y)
+
+	// z' = 2yz
+	z := new(big.Int).Mul(pt.Y, pt.Z)
+	c.mod(z.Lsh(z, 1))
+
+	return jacobianPoint{x, y, z}
+}
+
+// jacobianAdd adds two points in Jacobian coordinates.
+func (c *WeierstrassCurve) jacobianAdd(p1, p2 jacobianPoint) jacobianPoint {
+	if p1.Z.Sign() == 0 {
+		return p2
+	}
+	if p2.Z.Sign() == 0 {
+		return p1
+	}
+
+	// u1 = x1 z2^2, u2 = x2 z1^2, s1 = y1 z2^3, s2 = y2 z1^3
+	z1z1 := c.mod(new(big.Int).Mul(p1.Z, p1.Z))
+	z2z2 := c.mod(new(big.Int).Mul(p2.Z, p2.Z))
+	u1 := c.mod(new(big.Int).Mul(p1.X, z2z2))
+	u2 := c.mod(new(big.Int).Mul(p2.X, z1z1))
+	s1 := new(big.Int).Mul(p1.Y, p2.Z)
+	c.mod(s1.Mul(s1, z2z2))
+	s2 := new(big.Int).Mul(p2.Y, p1.Z)
+	c.mod(s2.Mul(s2, z1z1))
+
+	if u1.Cmp(u2) == 0 {
+		if s1.Cmp(s2) != 0 {
+			return jacobianPoint{big.NewInt(1), big.NewInt(1), big.NewInt(0)}
+		}
+		return c.jacobianDouble(p1)
+	}
+
+	// h = u2 - u1, r = s2 - s1
+	h := c.mod(new(big.Int).Sub(u2, u1))
+	r := c.mod(new(big.Int).Sub(s2, s1))
+	h2 := c.mod(new(big.Int).Mul(h, h))
+	h3 := c.mod(new(big.Int).Mul(h2, h))
+	u1h2 := c.mod(new(big.Int).Mul(u1, h2))
+
+	// x3 = r^2 - h^3 - 2 u1 h^2
+	x := new(big.Int).Mul(r, r)
+	x.Sub(x, h3)
+	x.Sub(x, new(big.Int).Lsh(u1h2, 1))
+	c.mod(x)
+
+	// y3 = r(u1 h^2 - x3) - s1 h^3
+	y := new(big.Int).Sub(u1h2, x)
+	y.Mul(y, r)
+	y.Sub(y, h3.Mul(h3, s1))
+	c.mod(y)
+
+	// z3 = h z1 z2
+	z := new(big.Int).Mul(h, p1.Z)
+	c.mod(z.Mul(z, p2.Z))
+
+	return jacobianPoint{x, y, z}
+}
+
+// ScalarMult computes k*pt by double-and-add in Jacobian coordinates.
+func (c *WeierstrassCurve) ScalarMult(pt Point, k *big.Int) Point {
+	if k.Sign() < 0 {
+		return c.ScalarMult(c.Neg(pt), new(big.Int).Neg(k))
+	}
+	q := c.toJacobian(pt)
+	res := c.toJacobian(Infinity)
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		res = c.jacobianDouble(res)
+		if k.Bit(i) == 1 {
+			res = c.jacobianAdd(res, q)
+		}
+	}
+	return c.fromJacobian(res)
+}
+
+// Group is the group of points on a curve, written additively, so that
+// generic discrete log algorithms can work with it.
+type Group struct {
+	Curve *WeierstrassCurve
+}
+
+func (g Group) Op(x, y Point) Point           { return g.Curve.Add(x, y) }
+func (g Group) Exp(x Point, k *big.Int) Point { return g.Curve.ScalarMult(x, k) }
+func (g Group) Equal(x, y Point) bool         { return x.Equal(y) }
+
+// Hash uses the low bits of the x coordinate.
+func (g Group) Hash(x Point) uint64 {
+	if x.IsInfinity() {
+		return 0
+	}
+	return x.X.Uint64()
+}
+
+// Domain is a curve together with a base point of prime order n, which is
+// everything needed for ECDH.
+type Domain struct {
+	Curve *WeierstrassCurve
+	G     Point
+	N     *big.Int
+}
+
+// GenerateKey returns a private key in [1, n) and the matching public key,
+// reading the randomness from rand, or crypto/rand if it's nil.
+func (d *Domain) GenerateKey(rand io.Reader) (priv *big.Int, pub Point) {
+	priv = random.New(rand).BigInt(new(big.Int).Sub(d.N, big.NewInt(1)))
+	priv.Add(priv, big.NewInt(1))
+	return priv, d.Curve.ScalarMult(d.G, priv)
+}
+
+// SharedSecret combines our private key with someone else's public key.
+func (d *Domain) SharedSecret(priv *big.Int, pub Point) Point {
+	return d.Curve.ScalarMult(pub, priv)
+}
+
+// MAC authenticates msg with a key derived from a shared point.
+func MAC(secret Point, msg []byte) []byte {
+	mac := hmac.New(sha256.New, secret.Bytes())
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,35 @@
+package ec
+
+import (
+	"io"
+	"math/big"
+)
+
+// DSKS finds a new domain and key pair under which sig is also a valid
+// signature of msg, given the public key pub it was made with. Nothing in
+// ECDSA ties a signature to a single key: the verifier checks that
+//
+// R = u1*G + u2*Q
+//
+// has the right x coordinate, so if we pick our own private key d' and set
+//
+// G' = (u1 + u2*d')^-1 * R, Q' = d'*G'
+//
+// then u1*G' + u2*Q' = (u1 + u2*d')G' = R as well. d' is made from rand, or
+// crypto/rand if it's nil.
+func DSKS(rand io.Reader, d *Domain, pub Point, msg []byte, sig Signature) (domain *Domain, priv *big.Int, newPub Point) {
+	c := d.Curve
+	u1, u2 := d.verifyScalars(msg, sig)
+	r := c.Add(c.ScalarMult(d.G, u1), c.ScalarMult(pub, u2))
+
+	for {
+		priv, _ = d.GenerateKey(rand)
+		t := new(big.Int).Mul(u2, priv)
+		t.Add(t, u1)
+		if t.ModInverse(t, d.N) == nil {
+			continue
+		}
+		domain = &Domain{Curve: c, G: c.ScalarMult(r, t), N: d.N}
+		return domain, priv, c.ScalarMult(domain.G, priv)
+	}
+}
@@ -0,0 +1,163 @@
+package ec
+
+import (
+	"flag"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestECArithmetic(t *testing.T) {
+	d := Challenge59Domain
+	c := d.Curve
+	testutil.AssertEqual(t, true, c.IsOnCurve(d.G))
+	testutil.AssertEqual(t, true, c.ScalarMult(d.G, d.N).IsInfinity())
+	testutil.AssertEqual(t, true, c.scalarMultAffine(d.G, d.N).IsInfinity())
+	testutil.AssertEqual(t, true, c.Add(d.G, c.Neg(d.G)).IsInfinity())
+
+	rand := detrand.New(1)
+	src := random.New(rand)
+	for i := 0; i < 10; i++ {
+		k := src.BigInt(d.N)
+		pt := c.ScalarMult(d.G, k)
+		testutil.AssertEqual(t, true, c.IsOnCurve(pt))
+		testutil.AssertEqual(t, true, pt.Equal(c.scalarMultAffine(d.G, k)))
+
+		// (k+1)G = kG + G, and 2kG = kG + kG
+		testutil.AssertEqual(t, true, c.Add(pt, d.G).Equal(c.ScalarMult(d.G, new(big.Int).Add(k, big.NewInt(1)))))
+		testutil.AssertEqual(t, true, c.Add(pt, pt).Equal(c.ScalarMult(d.G, new(big.Int).Lsh(k, 1))))
+		testutil.AssertEqual(t, true, c.fromJacobian(c.jacobianAdd(c.toJacobian(pt), c.toJacobian(pt))).Equal(c.Add(pt, pt)))
+	}
+
+	alicePriv, alicePub := d.GenerateKey(rand)
+	bobPriv, bobPub := d.GenerateKey(rand)
+	testutil.AssertEqual(t, true, d.SharedSecret(alicePriv, bobPub).Equal(d.SharedSecret(bobPriv, alicePub)))
+}
+
+func TestChallenge59(t *testing.T) {
+	rand := detrand.New(59)
+	src := random.New(rand)
+	for _, ic := range Challenge59InvalidCurves {
+		pt := ic.Curve.randomPoint(src)
+		testutil.AssertEqual(t, true, ic.Curve.IsOnCurve(pt))
+		testutil.AssertEqual(t, true, ic.Curve.ScalarMult(pt, ic.Order).IsInfinity())
+	}
+
+	bob := NewBob(rand, Challenge59Domain)
+	testutil.AssertEqual(t, bob.priv.String(), InvalidCurveAttack(rand, bob, Challenge59InvalidCurves).String())
+}
+
+func TestMontgomeryLadder(t *testing.T) {
+	d := Challenge60Domain
+	c := d.Curve
+	w := c.Weierstrass()
+	want := Challenge59Domain.Curve
+	testutil.AssertEqual(t, new(big.Int).Mod(want.A, want.P).String(), w.A.String())
+	testutil.AssertEqual(t, want.B.String(), w.B.String())
+	testutil.AssertEqual(t, "178", c.shift().String())
+
+	g := c.Lift(d.U)
+	testutil.AssertEqual(t, true, w.IsOnCurve(g))
+	testutil.AssertEqual(t, "182", g.X.String())
+	testutil.AssertEqual(t, "4", c.FromWeierstrass(g).String())
+	testutil.AssertEqual(t, "0", c.Ladder(d.U, d.N).String())
+
+	rand := detrand.New(2)
+	src := random.New(rand)
+	for i := 0; i < 10; i++ {
+		k := src.BigInt(d.N)
+		testutil.AssertEqual(t, c.FromWeierstrass(w.ScalarMult(g, k)).String(), c.Ladder(d.U, k).String())
+	}
+
+	// the twist has a different order
+	order := d.TwistOrder()
+	for {
+		u := src.BigInt(c.P)
+		if !c.OnCurve(u) {
+			testutil.AssertEqual(t, "0", c.Ladder(u, order).String())
+			break
+		}
+	}
+
+	alicePriv, alicePub := d.GenerateKey(rand)
+	bobPriv, bobPub := d.GenerateKey(rand)
+	testutil.AssertEqual(t, d.SharedSecret(alicePriv, bobPub).String(), d.SharedSecret(bobPriv, alicePub).String())
+}
+
+func TestChallenge60(t *testing.T) {
+	// the twist gives away Bob's key, up to sign, modulo its small factors
+	rand := detrand.New(60)
+	bob := NewMontgomeryBob(rand, Challenge60Domain)
+	x, m := twistResidue(random.New(rand), bob, 1<<22)
+	k := new(big.Int).Mod(bob.priv, m)
+	if k.Cmp(x) != 0 {
+		testutil.AssertEqual(t, k.Sub(m, k).String(), x.String())
+	}
+}
+
+// slow turns on the tests which take too long to run every time.
+var slow = flag.Bool("slow", false, "run the slow tests too")
+
+func TestChallenge60Kangaroo(t *testing.T) {
+	if !*slow {
+		t.Skip("the kangaroo takes a while over a 41 bit interval; run with -slow")
+	}
+	rand := detrand.New(60)
+	bob := NewMontgomeryBob(rand, Challenge60Domain)
+	k := TwistAttack(rand, bob, 1<<22)
+	if k.Cmp(bob.priv) != 0 {
+		testutil.AssertEqual(t, bob.priv.String(), new(big.Int).Sub(Challenge60Domain.N, k).String())
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	d := Challenge59Domain
+	rand := detrand.New(3)
+	priv, pub := d.GenerateKey(rand)
+	msg := []byte("hi mom")
+	sig := d.Sign(rand, priv, msg)
+	testutil.AssertEqual(t, true, d.Verify(pub, msg, sig))
+	testutil.AssertEqual(t, false, d.Verify(pub, []byte("hi dad"), sig))
+	_, other := d.GenerateKey(rand)
+
+	// the same randomness gives the same signature
+	testutil.AssertEqual(t, d.Sign(detrand.New(1), priv, msg), d.Sign(detrand.New(1), priv, msg))
+	testutil.AssertEqual(t, false, d.Verify(other, msg, sig))
+}
+
+func TestChallenge61(t *testing.T) {
+	msg := []byte("I'm the one who signed this")
+
+	d := Challenge59Domain
+	rand := detrand.New(61)
+	priv, pub := d.GenerateKey(rand)
+	sig := d.Sign(rand, priv, msg)
+
+	domain, evePriv, evePub := DSKS(rand, d, pub, msg, sig)
+	testutil.AssertEqual(t, true, domain.Verify(evePub, msg, sig))
+	testutil.AssertEqual(t, true, domain.Curve.ScalarMult(domain.G, evePriv).Equal(evePub))
+	testutil.AssertEqual(t, false, domain.Verify(evePub, []byte("something else"), sig))
+}
+
+func TestChallenge62(t *testing.T) {
+	d := Challenge59Domain
+	signer := NewBiasedNonceSigner(detrand.New(62), d, 8)
+
+	var sigs []SignedMessage
+	for i := 0; i < 22; i++ {
+		msg := []byte(fmt.Sprintf("message %d", i))
+		sig := signer.Sign(msg)
+		testutil.AssertEqual(t, true, d.Verify(signer.Pub, msg, sig))
+		sigs = append(sigs, SignedMessage{msg, sig})
+	}
+
+	key := RecoverBiasedNonceKey(d, signer.Pub, sigs, 8)
+	if key == nil {
+		t.Fatal("no key recovered")
+	}
+	testutil.AssertEqual(t, signer.priv.String(), key.String())
+}
@@ -0,0 +1,85 @@
+package ec
+
+import (
+	"crypto/sha256"
+	"io"
+	"math/big"
+)
+
+// Signature is an ECDSA signature.
+type Signature struct {
+	R, S *big.Int
+}
+
+// hashToInt hashes msg and turns the digest into a number, keeping only as
+// many of its leading bits as n has.
+func (d *Domain) hashToInt(msg []byte) *big.Int {
+	h := sha256.Sum256(msg)
+	e := new(big.Int).SetBytes(h[:])
+	if excess := len(h)*8 - d.N.BitLen(); excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// Sign signs msg with the private key priv, reading the nonces from rand,
+// or crypto/rand if it's nil.
+func (d *Domain) Sign(rand io.Reader, priv *big.Int, msg []byte) Signature {
+	return d.SignWithNonces(priv, msg, func() *big.Int {
+		k, _ := d.GenerateKey(rand)
+		return k
+	})
+}
+
+// SignWithNonces signs msg with the private key priv, taking the secret
+// nonce from nonce, which needs to return numbers in [1, n). Everything
+// falls apart if they're in any way predictable.
+func (d *Domain) SignWithNonces(priv *big.Int, msg []byte, nonce func() *big.Int) Signature {
+	e := d.hashToInt(msg)
+	for {
+		k := nonce()
+		r := d.Curve.ScalarMult(d.G, k).X
+		if r == nil {
+			continue
+		}
+		r = new(big.Int).Mod(r, d.N)
+		if r.Sign() == 0 {
+			continue
+		}
+		// s = (e + r*priv) / k
+		s := new(big.Int).Mul(r, priv)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, d.N))
+		s.Mod(s, d.N)
+		if s.Sign() == 0 {
+			continue
+		}
+		return Signature{r, s}
+	}
+}
+
+// Verify says whether sig is a valid signature of msg under the public key
+// pub.
+func (d *Domain) Verify(pub Point, msg []byte, sig Signature) bool {
+	if sig.R.Sign() <= 0 || sig.R.Cmp(d.N) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(d.N) >= 0 {
+		return false
+	}
+	u1, u2 := d.verifyScalars(msg, sig)
+	pt := d.Curve.Add(d.Curve.ScalarMult(d.G, u1), d.Curve.ScalarMult(pub, u2))
+	if pt.IsInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(pt.X, d.N).Cmp(sig.R) == 0
+}
+
+// verifyScalars returns u1 = e/s and u2 = r/s, which the verifier
+// multiplies the base point and the public key by respectively.
+func (d *Domain) verifyScalars(msg []byte, sig Signature) (u1, u2 *big.Int) {
+	w := new(big.Int).ModInverse(sig.S, d.N)
+	u1 = d.hashToInt(msg)
+	u1.Mul(u1, w)
+	u1.Mod(u1, d.N)
+	u2 = new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, d.N)
+	return u1, u2
+}
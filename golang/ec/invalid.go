@@ -0,0 +1,163 @@
+package ec
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/dlog"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/bigint"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Challenge59Domain is y^2 = x^3 - 95051x + 11279326 with a base point of
+// prime order. The whole curve has 8 times as many points.
+var Challenge59Domain = &Domain{
+	Curve: &WeierstrassCurve{
+		P: bigint.MustParse("233970423115425145524320034830162017933"),
+		A: big.NewInt(-95051),
+		B: big.NewInt(11279326),
+	},
+	G: Point{big.NewInt(182), bigint.MustParse("85518893674295321206118380980485522083")},
+	N: bigint.MustParse("29246302889428143187362802287225875743"),
+}
+
+// InvalidCurve is a curve sharing a with the real one, but with a different
+// b, along with how many points it has.
+type InvalidCurve struct {
+	Curve *WeierstrassCurve
+	Order *big.Int
+}
+
+// NewInvalidCurve returns the curve y^2 = x^3 + ax + b, where a and p come
+// from curve.
+func NewInvalidCurve(curve *WeierstrassCurve, b int64, order string) InvalidCurve {
+	return InvalidCurve{
+		Curve: &WeierstrassCurve{P: curve.P, A: curve.A, B: big.NewInt(b)},
+		Order: bigint.MustParse(order),
+	}
+}
+
+// Challenge59InvalidCurves all have orders with lots of small factors.
+var Challenge59InvalidCurves = []InvalidCurve{
+	NewInvalidCurve(Challenge59Domain.Curve, 210, "233970423115425145550826547352470124412"),
+	NewInvalidCurve(Challenge59Domain.Curve, 504, "233970423115425145544350131142039591210"),
+	NewInvalidCurve(Challenge59Domain.Curve, 727, "233970423115425145545378039958152057148"),
+}
+
+// Bob does ECDH with anyone who asks, and replies with a message
+// authenticated using the shared point. He never checks that the point he's
+// sent is on his curve.
+type Bob struct {
+	domain *Domain
+	priv   *big.Int
+	Pub    Point
+}
+
+// NewBob returns a Bob with a fresh key pair in domain, generated from
+// rand.
+func NewBob(rand io.Reader, domain *Domain) *Bob {
+	priv, pub := domain.GenerateKey(rand)
+	return &Bob{domain: domain, priv: priv, Pub: pub}
+}
+
+// Respond returns a message and its MAC under the point Bob shares with
+// whoever owns pub.
+func (b *Bob) Respond(pub Point) (msg, mac []byte) {
+	msg = []byte("crazy flamboyant for the rap enjoyment")
+	return msg, MAC(b.domain.SharedSecret(b.priv, pub), msg)
+}
+
+// randomPoint returns a random point on the curve, other than infinity.
+func (c *WeierstrassCurve) randomPoint(src *random.Source) Point {
+	for {
+		x := src.BigInt(c.P)
+		// only half of the x values have a point
+		if y := new(big.Int).ModSqrt(c.rhs(x), c.P); y != nil {
+			return Point{x, y}
+		}
+	}
+}
+
+// pointOfOrder returns a point of order r on a curve with order points,
+// where r is a prime factor of order. When r^2 divides the order the r part
+// of the group needn't be cyclic, so multiplying by order/r might kill every
+// point. Instead, strip r out of the order entirely and then multiply by r
+// until the next multiplication would reach infinity.
+func (c *WeierstrassCurve) pointOfOrder(src *random.Source, order, r *big.Int) Point {
+	cofactor := new(big.Int).Set(order)
+	q, m := new(big.Int), new(big.Int)
+	for {
+		q.DivMod(cofactor, r, m)
+		if m.Sign() != 0 {
+			break
+		}
+		cofactor.Set(q)
+	}
+
+	for {
+		pt := c.ScalarMult(c.randomPoint(src), cofactor)
+		if pt.IsInfinity() {
+			continue
+		}
+		for {
+			next := c.ScalarMult(pt, r)
+			if next.IsInfinity() {
+				return pt
+			}
+			pt = next
+		}
+	}
+}
+
+// bruteForceMAC finds k in [0, r) such that mac is the MAC of msg under
+// the shared point k*h.
+func bruteForceMAC(curve *WeierstrassCurve, h Point, r *big.Int, msg, mac []byte) *big.Int {
+	secret := Infinity
+	for k := big.NewInt(0); k.Cmp(r) < 0; k.Add(k, big.NewInt(1)) {
+		if hmac.Equal(mac, MAC(secret, msg)) {
+			return k
+		}
+		secret = curve.Add(secret, h)
+	}
+	panic(fmt.Sprintf("bruteForceMAC: no match for order %v", r))
+}
+
+// InvalidCurveAttack recovers Bob's private key. The formulas for adding
+// points don't depend on b, so if we send Bob a point on one of the invalid
+// curves, he'll happily multiply it by his key. Points of small order on
+// those curves let us brute force his key modulo each small factor of their
+// orders, just like the subgroup confinement attack. The points are made
+// from rand, or crypto/rand if it's nil.
+func InvalidCurveAttack(rand io.Reader, bob *Bob, curves []InvalidCurve) *big.Int {
+	src := random.New(rand)
+	var residues, moduli []*big.Int
+	product := big.NewInt(1)
+	used := make(map[string]bool)
+
+	for _, ic := range curves {
+		for _, f := range dlog.SmallFactors(ic.Order, 1<<16) {
+			if product.Cmp(bob.domain.N) > 0 {
+				break
+			}
+			// the moduli need to be coprime
+			if used[f.String()] {
+				continue
+			}
+			used[f.String()] = true
+
+			h := ic.Curve.pointOfOrder(src, ic.Order, f)
+			msg, mac := bob.Respond(h)
+			residues = append(residues, bruteForceMAC(ic.Curve, h, f, msg, mac))
+			moduli = append(moduli, f)
+			product.Mul(product, f)
+		}
+	}
+
+	if product.Cmp(bob.domain.N) <= 0 {
+		panic("InvalidCurveAttack: not enough small factors")
+	}
+	x, _ := dlog.CRT(residues, moduli)
+	return x
+}
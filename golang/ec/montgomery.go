@@ -0,0 +1,168 @@
+package ec
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// MontgomeryCurve is the Montgomery curve v^2 = u^3 + au^2 + u over the
+// integers modulo the prime p. (The general form has a coefficient in front
+// of v^2, but we only need it to be 1.)
+type MontgomeryCurve struct {
+	P, A *big.Int
+}
+
+func (c *MontgomeryCurve) mod(x *big.Int) *big.Int {
+	return x.Mod(x, c.P)
+}
+
+// rhs returns u^3 + au^2 + u, which is v^2 for points on the curve.
+func (c *MontgomeryCurve) rhs(u *big.Int) *big.Int {
+	res := new(big.Int).Add(u, c.A)
+	res.Mul(res, u)
+	res.Add(res, big.NewInt(1))
+	res.Mul(res, u)
+	return c.mod(res)
+}
+
+// OnCurve says whether u is the u coordinate of a point on the curve. Every
+// u which isn't is on the quadratic twist instead.
+func (c *MontgomeryCurve) OnCurve(u *big.Int) bool {
+	return big.Jacobi(c.rhs(u), c.P) >= 0
+}
+
+// Ladder computes the u coordinate of k times the point with u coordinate
+// u, using the Montgomery ladder. It never needs v, so it'll happily work
+// with a u from the twist. The point at infinity comes out as 0.
+func (c *MontgomeryCurve) Ladder(u, k *big.Int) *big.Int {
+	u2, w2 := big.NewInt(1), big.NewInt(0)
+	u3, w3 := new(big.Int).Set(u), big.NewInt(1)
+	t1, t2 := new(big.Int), new(big.Int)
+
+	bits := c.P.BitLen()
+	if k.BitLen() > bits {
+		bits = k.BitLen()
+	}
+	for i := bits - 1; i >= 0; i-- {
+		if k.Bit(i) == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+
+		// u3, w3 = (u2*u3 - w2*w3)^2, u*(u2*w3 - w2*u3)^2
+		t1.Mul(u2, u3)
+		t2.Mul(w2, w3)
+		nu3 := c.mod(new(big.Int).Sub(t1, t2))
+		nu3.Mul(nu3, nu3)
+		t1.Mul(u2, w3)
+		t2.Mul(w2, u3)
+		nw3 := c.mod(new(big.Int).Sub(t1, t2))
+		nw3.Mul(nw3, nw3)
+		nw3.Mul(nw3, u)
+
+		// u2, w2 = (u2^2 - w2^2)^2, 4*u2*w2*(u2^2 + a*u2*w2 + w2^2)
+		uu := new(big.Int).Mul(u2, u2)
+		ww := new(big.Int).Mul(w2, w2)
+		uw := new(big.Int).Mul(u2, w2)
+		nu2 := c.mod(new(big.Int).Sub(uu, ww))
+		nu2.Mul(nu2, nu2)
+		nw2 := new(big.Int).Mul(c.A, uw)
+		nw2.Add(nw2, uu)
+		nw2.Add(nw2, ww)
+		nw2.Mul(nw2, uw.Lsh(uw, 2))
+
+		u2, w2 = c.mod(nu2), c.mod(nw2)
+		u3, w3 = c.mod(nu3), c.mod(nw3)
+
+		if k.Bit(i) == 1 {
+			u2, u3 = u3, u2
+			w2, w3 = w3, w2
+		}
+	}
+
+	if w2.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	return c.mod(u2.Mul(u2, new(big.Int).ModInverse(w2, c.P)))
+}
+
+// shift is a/3, the amount u coordinates move by in Weierstrass form.
+func (c *MontgomeryCurve) shift() *big.Int {
+	s := new(big.Int).ModInverse(big.NewInt(3), c.P)
+	return c.mod(s.Mul(s, c.A))
+}
+
+// Weierstrass returns the same curve in short Weierstrass form, which is
+// y^2 = x^3 + (3 - a^2)/3 x + (2a^3 - 9a)/27 with x = u + a/3 and y = v.
+func (c *MontgomeryCurve) Weierstrass() *WeierstrassCurve {
+	inv3 := new(big.Int).ModInverse(big.NewInt(3), c.P)
+	inv27 := new(big.Int).Exp(inv3, big.NewInt(3), c.P)
+
+	a2 := new(big.Int).Mul(c.A, c.A)
+	wa := new(big.Int).Sub(big.NewInt(3), a2)
+	wa.Mul(wa, inv3)
+
+	wb := new(big.Int).Mul(a2, big.NewInt(2))
+	wb.Sub(wb, big.NewInt(9))
+	wb.Mul(wb, c.A)
+	wb.Mul(wb, inv27)
+
+	return &WeierstrassCurve{P: c.P, A: c.mod(wa), B: c.mod(wb)}
+}
+
+// ToWeierstrass maps the point (u, v) to Weierstrass form.
+func (c *MontgomeryCurve) ToWeierstrass(u, v *big.Int) Point {
+	return Point{c.mod(new(big.Int).Add(u, c.shift())), new(big.Int).Set(v)}
+}
+
+// FromWeierstrass returns the u coordinate of a point in Weierstrass form.
+func (c *MontgomeryCurve) FromWeierstrass(pt Point) *big.Int {
+	if pt.IsInfinity() {
+		return big.NewInt(0)
+	}
+	return c.mod(new(big.Int).Sub(pt.X, c.shift()))
+}
+
+// Lift returns one of the two points in Weierstrass form with the given u
+// coordinate. There's no telling which one, since u only determines v up to
+// sign. It panics if u isn't on the curve.
+func (c *MontgomeryCurve) Lift(u *big.Int) Point {
+	v := new(big.Int).ModSqrt(c.rhs(u), c.P)
+	if v == nil {
+		panic("lift: u is on the twist")
+	}
+	return c.ToWeierstrass(u, v)
+}
+
+// MontgomeryDomain is a curve along with the u coordinate of a base point
+// of prime order n. The whole curve has n times cofactor points.
+type MontgomeryDomain struct {
+	Curve    *MontgomeryCurve
+	U        *big.Int
+	N        *big.Int
+	Cofactor *big.Int
+}
+
+// TwistOrder returns the number of points on the quadratic twist. Between
+// them, the curve and its twist have one point for each u, two for each
+// nonzero v, and a point at infinity each, so that's 2p + 2 - order.
+func (d *MontgomeryDomain) TwistOrder() *big.Int {
+	res := new(big.Int).Lsh(d.Curve.P, 1)
+	res.Add(res, big.NewInt(2))
+	return res.Sub(res, new(big.Int).Mul(d.N, d.Cofactor))
+}
+
+// GenerateKey returns a private key in [1, n) and the matching public key,
+// reading the randomness from rand, or crypto/rand if it's nil.
+func (d *MontgomeryDomain) GenerateKey(rand io.Reader) (priv, pub *big.Int) {
+	priv = random.New(rand).BigInt(new(big.Int).Sub(d.N, big.NewInt(1)))
+	priv.Add(priv, big.NewInt(1))
+	return priv, d.Curve.Ladder(d.U, priv)
+}
+
+// SharedSecret combines our private key with someone else's public key.
+func (d *MontgomeryDomain) SharedSecret(priv, pub *big.Int) *big.Int {
+	return d.Curve.Ladder(pub, priv)
+}
@@ -0,0 +1,124 @@
+package ec
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/lattice"
+)
+
+// BiasedNonceSigner signs messages with ECDSA, but the nonces it uses always
+// have their low bias bits set to zero.
+type BiasedNonceSigner struct {
+	domain *Domain
+	priv   *big.Int
+	Pub    Point
+	bias   uint
+	rand   io.Reader
+}
+
+// NewBiasedNonceSigner returns a signer with a fresh key pair in domain,
+// whose nonces are multiples of 2^bias. The key and the nonces are read
+// from rand, or crypto/rand if it's nil.
+func NewBiasedNonceSigner(rand io.Reader, domain *Domain, bias uint) *BiasedNonceSigner {
+	priv, pub := domain.GenerateKey(rand)
+	return &BiasedNonceSigner{domain: domain, priv: priv, Pub: pub, bias: bias, rand: rand}
+}
+
+// Sign signs msg with a biased nonce.
+func (b *BiasedNonceSigner) Sign(msg []byte) Signature {
+	return b.domain.SignWithNonces(b.priv, msg, func() *big.Int {
+		for {
+			k, _ := b.domain.GenerateKey(b.rand)
+			k.Rsh(k, b.bias)
+			if k.Lsh(k, b.bias).Sign() != 0 {
+				return k
+			}
+		}
+	})
+}
+
+// SignedMessage is a message together with its signature.
+type SignedMessage struct {
+	Msg []byte
+	Sig Signature
+}
+
+// RecoverBiasedNonceKey recovers the private key behind pub from signatures
+// whose nonces are all multiples of 2^l. For each signature
+//
+// s = (H + r*d) / k (mod n)
+//
+// and writing k = 2^l * b, we get d*t - u = b (mod n) for
+//
+// t = r / (s*2^l), u = H / (-s*2^l)
+//
+// where every b is less than n/2^l. That's the hidden number problem: we
+// know lots of multiples of d which are unusually close to multiples of n.
+// The lattice spanned by the rows
+//
+// n  0  ...  0  0  0
+// 0  n  ...  0  0  0
+// ...
+// t1 t2 ... tm ct  0
+// u1 u2 ... um  0 cu
+//
+// with ct = 1/2^l and cu = n/2^l then contains the short vector
+//
+// d*t - u + (some multiples of n) = (b1, ..., bm, d*ct, -cu)
+//
+// which LLL ought to find, and d drops out of the second to last entry.
+func RecoverBiasedNonceKey(d *Domain, pub Point, sigs []SignedMessage, l uint) *big.Int {
+	m := len(sigs)
+	twoL := new(big.Int).Lsh(big.NewInt(1), l)
+	rat := func(x *big.Int) *big.Rat { return new(big.Rat).SetInt(x) }
+
+	basis := make([][]*big.Rat, m+2)
+	for i := range basis {
+		basis[i] = make([]*big.Rat, m+2)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Rat)
+		}
+	}
+	for i := 0; i < m; i++ {
+		basis[i][i].SetInt(d.N)
+	}
+
+	ct := new(big.Rat).SetFrac(big.NewInt(1), twoL)
+	cu := new(big.Rat).SetFrac(d.N, twoL)
+	for i, sm := range sigs {
+		// 1 / (s*2^l)
+		inv := new(big.Int).Mul(sm.Sig.S, twoL)
+		inv.ModInverse(inv, d.N)
+
+		t := new(big.Int).Mul(sm.Sig.R, inv)
+		basis[m][i] = rat(t.Mod(t, d.N))
+
+		u := new(big.Int).Mul(d.hashToInt(sm.Msg), inv)
+		u.Neg(u)
+		basis[m+1][i] = rat(u.Mod(u, d.N))
+	}
+	basis[m][m] = ct
+	basis[m+1][m+1] = cu
+
+	for _, row := range lattice.LLL(basis, lattice.Delta) {
+		sign := row[m+1].Cmp(cu)
+		if sign != 0 && new(big.Rat).Neg(row[m+1]).Cmp(cu) != 0 {
+			continue
+		}
+		// row is ±(b1, ..., bm, d*ct, -cu)
+		x := new(big.Rat).Quo(row[m], ct)
+		if !x.IsInt() {
+			continue
+		}
+		key := new(big.Int).Set(x.Num())
+		if sign == 0 {
+			key.Neg(key)
+		}
+		key.Mod(key, d.N)
+		if d.Curve.ScalarMult(d.G, key).Equal(pub) {
+			return key
+		}
+	}
+	return nil
+}
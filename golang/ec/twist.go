@@ -0,0 +1,201 @@
+package ec
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/dh"
+	"github.com/jabley/matasano-crypto-challenges/golang/dlog"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Challenge60Domain is v^2 = u^3 + 534u^2 + u, which is the curve from
+// challenge 59 in Montgomery form, with u = x - 178. The base point is the
+// same one.
+var Challenge60Domain = &MontgomeryDomain{
+	Curve: &MontgomeryCurve{
+		P: Challenge59Domain.Curve.P,
+		A: big.NewInt(534),
+	},
+	U:        big.NewInt(4),
+	N:        Challenge59Domain.N,
+	Cofactor: big.NewInt(8),
+}
+
+// MontgomeryBob does ECDH using only u coordinates. He doesn't check whether
+// the u he's sent is on his curve or on the twist.
+type MontgomeryBob struct {
+	domain *MontgomeryDomain
+	priv   *big.Int
+	Pub    *big.Int
+}
+
+// NewMontgomeryBob returns a MontgomeryBob with a fresh key pair in domain,
+// generated from rand.
+func NewMontgomeryBob(rand io.Reader, domain *MontgomeryDomain) *MontgomeryBob {
+	priv, pub := domain.GenerateKey(rand)
+	return &MontgomeryBob{domain: domain, priv: priv, Pub: pub}
+}
+
+// Respond returns a message and its MAC under the secret Bob shares with
+// whoever owns pub.
+func (b *MontgomeryBob) Respond(pub *big.Int) (msg, mac []byte) {
+	msg = []byte("crazy flamboyant for the rap enjoyment")
+	return msg, dh.MAC(b.domain.SharedSecret(b.priv, pub), msg)
+}
+
+// twistPointOfOrder returns the u coordinate of a point on the twist, which
+// has order points, whose order is the product of the given odd primes.
+func (c *MontgomeryCurve) twistPointOfOrder(src *random.Source, order *big.Int, factors ...*big.Int) *big.Int {
+	r := big.NewInt(1)
+	for _, f := range factors {
+		r.Mul(r, f)
+	}
+	cofactor := new(big.Int).Div(order, r)
+
+next:
+	for {
+		u := src.BigInt(c.P)
+		if c.OnCurve(u) {
+			continue
+		}
+		u = c.Ladder(u, cofactor)
+		if c.Ladder(u, r).Sign() != 0 {
+			continue
+		}
+		for _, f := range factors {
+			if c.Ladder(u, new(big.Int).Div(r, f)).Sign() == 0 {
+				continue next
+			}
+		}
+		return u
+	}
+}
+
+// bruteForceLadderMAC finds k in [0, r/2] such that mac is the MAC of msg
+// under the u coordinate of k times the point with u coordinate u, which
+// has order r. Since k and -k give the same u coordinate, the key we're
+// after is k or -k modulo r.
+//
+// The ladder is far too slow to call for each k, so this steps through the
+// multiples using differential addition: from the u coordinates of kP, P and
+// (k-1)P we get the u coordinate of (k+1)P, in projective coordinates.
+func bruteForceLadderMAC(c *MontgomeryCurve, u, r *big.Int, msg, mac []byte) *big.Int {
+	// (x0 : z0) is (k-1)P and (x1 : z1) is kP
+	x0, z0 := big.NewInt(1), big.NewInt(0)
+	x1, z1 := new(big.Int).Set(u), big.NewInt(1)
+	t, secret := new(big.Int), new(big.Int)
+
+	half := new(big.Int).Rsh(r, 1)
+	if hmac.Equal(mac, dh.MAC(big.NewInt(0), msg)) {
+		return big.NewInt(0)
+	}
+	for k := big.NewInt(1); k.Cmp(half) <= 0; k.Add(k, big.NewInt(1)) {
+		secret.ModInverse(z1, c.P)
+		c.mod(secret.Mul(secret, x1))
+		if hmac.Equal(mac, dh.MAC(secret, msg)) {
+			return k
+		}
+
+		var x2, z2 *big.Int
+		if k.Cmp(big.NewInt(1)) == 0 {
+			x2 = c.Ladder(u, big.NewInt(2))
+			z2 = big.NewInt(1)
+		} else {
+			// x2 = z0 (x1 u - z1)^2, z2 = x0 (x1 - z1 u)^2
+			x2 = new(big.Int).Mul(x1, u)
+			c.mod(x2.Sub(x2, z1))
+			x2.Mul(x2, x2)
+			c.mod(x2.Mul(x2, z0))
+			z2 = t.Mul(z1, u)
+			z2 = c.mod(new(big.Int).Sub(x1, z2))
+			z2.Mul(z2, z2)
+			c.mod(z2.Mul(z2, x0))
+		}
+		x0, z0, x1, z1 = x1, z1, x2, z2
+	}
+	panic(fmt.Sprintf("bruteForceLadderMAC: no match for order %v", r))
+}
+
+// TwistAttack recovers Bob's private key, up to sign, when all he uses is
+// u coordinates. Every u is on either the curve or its twist, and the twist
+// has an order with plenty of small factors. That gets us the key modulo
+// each factor r, but only up to sign, since u can't tell k and -k apart.
+//
+// To line up the signs we send Bob a point of order r0*r for each later
+// factor r, and check which of the two ways of combining the residues he
+// used. That leaves us with k = ±x (mod m), and Pollard's kangaroo finishes
+// the job in Weierstrass form. We don't know which of the two points with
+// Bob's u coordinate is his public key, but one of them is (x + jm)G with
+// |j| <= n/m, so we look for both.
+//
+// The key we get back is either Bob's key or n minus it, which give the
+// same public key and the same shared secrets.
+//
+// The points and the kangaroo's shifts are made from rand, or crypto/rand
+// if it's nil.
+func TwistAttack(rand io.Reader, bob *MontgomeryBob, limit int64) *big.Int {
+	d := bob.domain
+	c := d.Curve
+	x, m := twistResidue(random.New(rand), bob, limit)
+
+	w := c.Weierstrass()
+	grp := Group{w}
+	g := c.Lift(d.U)
+	if c.FromWeierstrass(g).Cmp(d.U) != 0 {
+		panic("TwistAttack: bad base point")
+	}
+	// y - xG = j(mG), for y = ±pub
+	y := c.Lift(bob.Pub)
+	xg := w.Neg(w.ScalarMult(g, x))
+	ys := []Point{w.Add(y, xg), w.Add(w.Neg(y), xg)}
+	bound := new(big.Int).Div(d.N, m)
+	bound.Add(bound, big.NewInt(1))
+	_, j := dlog.KangarooLogOneOf(rand, grp, w.ScalarMult(g, m), ys, new(big.Int).Neg(bound), bound)
+
+	k := j.Mul(j, m)
+	k.Add(k, x)
+	return k.Mod(k, d.N)
+}
+
+// twistResidue finds x and m such that Bob's key is ±x modulo m, where m
+// is the product of the odd factors of the twist's order below limit.
+func twistResidue(src *random.Source, bob *MontgomeryBob, limit int64) (x, m *big.Int) {
+	d := bob.domain
+	c := d.Curve
+	order := d.TwistOrder()
+
+	var residues, moduli []*big.Int
+	for _, r := range dlog.SmallFactors(order, limit) {
+		// 2 torsion looks just like the point at infinity
+		if r.Cmp(big.NewInt(2)) == 0 {
+			continue
+		}
+		u := c.twistPointOfOrder(src, order, r)
+		msg, mac := bob.Respond(u)
+		residues = append(residues, bruteForceLadderMAC(c, u, r, msg, mac))
+		moduli = append(moduli, r)
+	}
+
+	// the first factor with a nonzero residue fixes the sign of the rest
+	pivot := -1
+	for i, a := range residues {
+		if a.Sign() == 0 {
+			continue
+		}
+		if pivot < 0 {
+			pivot = i
+			continue
+		}
+		rs := []*big.Int{moduli[pivot], moduli[i]}
+		u := c.twistPointOfOrder(src, order, rs...)
+		msg, mac := bob.Respond(u)
+		guess, _ := dlog.CRT([]*big.Int{residues[pivot], a}, rs)
+		if !hmac.Equal(mac, dh.MAC(c.Ladder(u, guess), msg)) {
+			a.Sub(moduli[i], a)
+		}
+	}
+	return dlog.CRT(residues, moduli)
+}
@@ -0,0 +1,234 @@
+package gcm
+
+import (
+	"io"
+	"math/bits"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/gf2"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// Message is some additional data, a ciphertext and the tag GCM gave
+// them.
+type Message struct {
+	AD, CT, Tag []byte
+}
+
+// Eval returns the value of f at x.
+func (f Poly) Eval(x GF128) GF128 {
+	var res GF128
+	for i := len(f) - 1; i >= 0; i-- {
+		res = res.Mul(x).Add(f[i])
+	}
+	return res
+}
+
+// tagPoly returns the polynomial in h which equals the tag mask s for
+// m: with GHASH blocks b1, ..., bn it's
+//
+// b1*h^n + ... + bn*h + tag
+//
+// since the tag is the GHASH plus s.
+func tagPoly(m Message) Poly {
+	blocks := gcmBlocks(m.AD, m.CT)
+	f := make(Poly, len(blocks)+1)
+	for i, b := range blocks {
+		f[len(blocks)-i] = b
+	}
+	f[0] = GF128FromBytes(m.Tag)
+	return f.Trim()
+}
+
+// RecoverKey finds the candidates for the authentication key h given
+// messages which were all encrypted under the same key and nonce. They all
+// share the same s, so h is a root of the difference between the tag
+// polynomials of any two of them. Any further messages weed out the roots
+// which don't give them all the same s. Finding the roots takes some
+// randomness, which is read from rand, or crypto/rand if it's nil.
+func RecoverKey(rand io.Reader, msgs []Message) []GF128 {
+	if len(msgs) < 2 {
+		panic("RecoverKey: need at least two messages")
+	}
+	polys := make([]Poly, len(msgs))
+	for i, m := range msgs {
+		polys[i] = tagPoly(m)
+	}
+
+	var res []GF128
+	for _, h := range polys[0].Add(polys[1]).Roots(rand) {
+		s := polys[0].Eval(h)
+		ok := true
+		for _, f := range polys[2:] {
+			ok = ok && f.Eval(h) == s
+		}
+		if ok {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// ForgeTag returns a valid tag for ad and ct under the nonce used for m,
+// given the authentication key h.
+func ForgeTag(h GF128, m Message, ad, ct []byte) []byte {
+	s := tagPoly(m).Eval(h)
+	return ghash(h, gcmBlocks(ad, ct)).Add(s).Bytes()
+}
+
+// TruncatedAttack is Ferguson's attack on GCM with short tags. We have a
+// single message of 2^n blocks, and an oracle which says whether a
+// ciphertext and tag are valid under the same nonce. The blocks which GHASH
+// multiplies by h^(2^i) are special: squaring is linear over GF(2), so if we
+// add e_i to each of them the tag changes by
+//
+// sum e_i * h^(2^i) = Ad * h
+//
+// for a 128 by 128 bit matrix Ad which we can compute, and which depends
+// linearly on the bits of the e_i. That means we can pick the e_i so that
+// some rows of Ad are zero, and those bits of the tag don't change no
+// matter what h is. With only a few tag bits left to chance, forgeries
+// succeed often, and each one tells us that the rest of the tag rows of Ad
+// are orthogonal to h. Once we have enough of those, h is the only nonzero
+// vector left.
+type TruncatedAttack struct {
+	ct      []byte
+	tag     []byte
+	n       int
+	tagBits int
+	oracle  func(ct, tag []byte) bool
+	src     *random.Source
+
+	squares []*gf2.Matrix // squares[i] maps h to h^(2^i)
+	xPowers []*gf2.Matrix // xPowers[k] multiplies by x^k
+}
+
+// NewTruncatedAttack sets up an attack given a valid message and tag,
+// where the message is 2^n whole blocks. The forgeries it tries are picked
+// using rand, or crypto/rand if it's nil.
+func NewTruncatedAttack(rand io.Reader, ct, tag []byte, oracle func(ct, tag []byte) bool) *TruncatedAttack {
+	blocks := len(ct) / 16
+	n := bits.Len(uint(blocks)) - 1
+	if len(ct)%16 != 0 || blocks != 1<<uint(n) || n < 1 {
+		panic("NewTruncatedAttack: need a power of two number of blocks")
+	}
+
+	a := &TruncatedAttack{ct: ct, tag: tag, n: n, tagBits: 8 * len(tag), oracle: oracle, src: random.New(rand)}
+	s := gf128SquareMatrix()
+	a.squares = []*gf2.Matrix{gf2.Identity(128)}
+	for i := 1; i <= n; i++ {
+		a.squares = append(a.squares, s.Mul(a.squares[i-1]))
+	}
+	xi := GF128One
+	for k := 0; k < 128; k++ {
+		a.xPowers = append(a.xPowers, xi.mulMatrix())
+		xi = xi.Mul(GF128X)
+	}
+	return a
+}
+
+// blockIndex returns which ciphertext block gets multiplied by h^(2^i).
+// The last block is multiplied by h^2, after the length block.
+func (a *TruncatedAttack) blockIndex(i int) int {
+	return 1<<uint(a.n) + 1 - 1<<uint(i)
+}
+
+// errorMatrix returns Ad for the changes e, where e_i for i = 1 ... n are
+// packed into e one after the other.
+func (a *TruncatedAttack) errorMatrix(e gf2.Vec) *gf2.Matrix {
+	ad := gf2.NewMatrix(128, 128)
+	for i := 1; i <= a.n; i++ {
+		ei := gf128FromVec(gf2.Vec{e[2*(i-1)], e[2*(i-1)+1]})
+		ad = ad.Add(ei.mulMatrix().Mul(a.squares[i]))
+	}
+	return ad
+}
+
+// dependencies returns the matrix T taking the bits of e to the first
+// zeroRows rows of Ad*X, flattened. Anything in its kernel zeroes those
+// rows. Since Ad is linear in e, column j of T is just what we get from
+// setting bit j of e on its own, which is x^k for some e_i.
+func (a *TruncatedAttack) dependencies(x *gf2.Matrix, zeroRows int) *gf2.Matrix {
+	squaredX := make([]*gf2.Matrix, a.n+1)
+	for i := 1; i <= a.n; i++ {
+		squaredX[i] = a.squares[i].Mul(x)
+	}
+
+	d := x.Cols
+	tt := gf2.NewMatrix(128*a.n, zeroRows*d)
+	for i := 1; i <= a.n; i++ {
+		for k := 0; k < 128; k++ {
+			col := tt.Row(128*(i-1) + k)
+			mk := a.xPowers[k]
+			for r := 0; r < zeroRows; r++ {
+				// row r of x^k * S^i * X
+				row := gf2.NewVec(d)
+				for j := 0; j < 128; j++ {
+					if mk.Get(r, j) {
+						row.XORIn(squaredX[i].Row(j))
+					}
+				}
+				for c := 0; c < d; c++ {
+					if row.Bit(c) {
+						col.SetBit(r*d+c, true)
+					}
+				}
+			}
+		}
+	}
+	return tt.Transpose()
+}
+
+// forge applies the changes e to the ciphertext.
+func (a *TruncatedAttack) forge(e gf2.Vec) []byte {
+	forged := append([]byte(nil), a.ct...)
+	for i := 1; i <= a.n; i++ {
+		ei := gf128FromVec(gf2.Vec{e[2*(i-1)], e[2*(i-1)+1]}).Bytes()
+		j := 16 * a.blockIndex(i)
+		xorcipher.XORBytes(forged[j:j+16], forged[j:j+16], ei)
+	}
+	return forged
+}
+
+// RecoverKey runs the attack, and returns h along with how many forgeries
+// it tried.
+func (a *TruncatedAttack) RecoverKey() (GF128, int) {
+	known := gf2.NewMatrix(0, 128)
+	queries := 0
+	for {
+		basis := known.Kernel()
+		if len(basis) == 1 {
+			return gf128FromVec(basis[0]), queries
+		}
+		x := gf2.FromColumns(128, basis)
+
+		// zero as many rows as we can while leaving T a kernel to pick from
+		zeroRows := (128*a.n - 1) / x.Cols
+		if zeroRows > a.tagBits-1 {
+			zeroRows = a.tagBits - 1
+		}
+		candidates := a.dependencies(x, zeroRows).Kernel()
+
+		for {
+			e := gf2.NewVec(128 * a.n)
+			for _, v := range candidates {
+				if a.src.Int(2) == 1 {
+					e.XORIn(v)
+				}
+			}
+			if e.IsZero() {
+				continue
+			}
+			queries++
+			if !a.oracle(a.forge(e), a.tag) {
+				continue
+			}
+			// the rest of the tag rows of Ad are orthogonal to h
+			ad := a.errorMatrix(e)
+			for r := zeroRows; r < a.tagBits; r++ {
+				known.AppendRow(ad.Row(r))
+			}
+			break
+		}
+	}
+}
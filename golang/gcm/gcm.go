@@ -0,0 +1,128 @@
+// Package gcm is AES-GCM written out by hand, along with the attacks on
+// it when a nonce is reused or the tags are short.
+package gcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// NonceSize is the only nonce size this GCM supports, and TagSize is
+// the size of a full tag.
+const (
+	NonceSize = 12
+	TagSize   = 16
+)
+
+// GCM is AES-GCM built from the ground up, so that we can see what goes
+// wrong when a nonce is reused. The ciphertext is CTR mode, and the tag is
+// GHASH, a polynomial in the authentication key h evaluated over the
+// additional data and ciphertext, masked with an encrypted counter block.
+type GCM struct {
+	block   cipher.Block
+	h       GF128
+	tagSize int
+}
+
+// New returns a GCM with full length tags using the block cipher b, which
+// must have 128 bit blocks.
+func New(b cipher.Block) *GCM {
+	return NewWithTagSize(b, TagSize)
+}
+
+// NewWithTagSize returns a GCM which truncates its tags to tagSize
+// bytes. Anything much shorter than the full 16 bytes is asking for
+// trouble, but that's the point.
+func NewWithTagSize(b cipher.Block, tagSize int) *GCM {
+	if b.BlockSize() != 16 {
+		panic("NewWithTagSize: need a 128 bit block cipher")
+	}
+	if tagSize < 1 || tagSize > TagSize {
+		panic(fmt.Sprintf("NewWithTagSize: bad tag size %d", tagSize))
+	}
+	h := make([]byte, 16)
+	b.Encrypt(h, h)
+	return &GCM{block: b, h: GF128FromBytes(h), tagSize: tagSize}
+}
+
+// gcmBlocks splits the additional data and ciphertext into the blocks GHASH
+// runs over: each of them padded with zeros to a whole number of blocks,
+// followed by a block holding both their lengths in bits.
+func gcmBlocks(ad, ct []byte) []GF128 {
+	var res []GF128
+	for _, buf := range [][]byte{ad, ct} {
+		for i := 0; i < len(buf); i += 16 {
+			block := make([]byte, 16)
+			copy(block, buf[i:])
+			res = append(res, GF128FromBytes(block))
+		}
+	}
+	return append(res, GF128{uint64(len(ad)) * 8, uint64(len(ct)) * 8})
+}
+
+// ghash evaluates b1*h^n + b2*h^(n-1) + ... + bn*h, using Horner's rule.
+func ghash(h GF128, blocks []GF128) GF128 {
+	var g GF128
+	for _, b := range blocks {
+		g = g.Add(b).Mul(h)
+	}
+	return g
+}
+
+// ctr runs GCM's flavour of CTR mode over in, starting from the counter
+// block after j0. Only the last 32 bits of the counter are incremented,
+// big-endian.
+func (g *GCM) ctr(nonce, in []byte) []byte {
+	iv := g.j0(nonce)
+	iv[15]++
+	s, err := modes.NewCounterStream(g.block, iv, modes.CounterLayout{Bits: 32})
+	if err != nil {
+		panic(err)
+	}
+	out := make([]byte, len(in))
+	s.XORKeyStream(out, in)
+	return out
+}
+
+// j0 is the counter block for nonce. It's used to mask the tag, and the
+// keystream starts with the one after it.
+func (g *GCM) j0(nonce []byte) []byte {
+	if len(nonce) != NonceSize {
+		panic(fmt.Sprintf("GCM: nonce must be %d bytes", NonceSize))
+	}
+	j := make([]byte, 16)
+	copy(j, nonce)
+	j[15] = 1
+	return j
+}
+
+// tag computes the authentication tag for ad and ct under nonce, truncated
+// to the tag size.
+func (g *GCM) tag(nonce, ad, ct []byte) []byte {
+	s := make([]byte, 16)
+	g.block.Encrypt(s, g.j0(nonce))
+	return xorcipher.XOR(ghash(g.h, gcmBlocks(ad, ct)).Bytes(), s)[:g.tagSize]
+}
+
+// Seal encrypts and authenticates plainText, authenticates ad, and returns
+// the ciphertext with the tag appended, just like cipher.AEAD.Seal.
+func (g *GCM) Seal(nonce, plainText, ad []byte) []byte {
+	ct := g.ctr(nonce, plainText)
+	return append(ct, g.tag(nonce, ad, ct)...)
+}
+
+// Open checks the tag at the end of cipherText and decrypts the rest.
+func (g *GCM) Open(nonce, cipherText, ad []byte) ([]byte, error) {
+	if len(cipherText) < g.tagSize {
+		return nil, fmt.Errorf("GCM: ciphertext too short")
+	}
+	ct, tag := cipherText[:len(cipherText)-g.tagSize], cipherText[len(cipherText)-g.tagSize:]
+	if subtle.ConstantTimeCompare(tag, g.tag(nonce, ad, ct)) != 1 {
+		return nil, fmt.Errorf("GCM: message authentication failed")
+	}
+	return g.ctr(nonce, ct), nil
+}
@@ -0,0 +1,156 @@
+package gcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+func TestGF128(t *testing.T) {
+	src := random.New(detrand.New(1))
+	a, b := randomGF128(src), randomGF128(src)
+	testutil.AssertEqual(t, a, a.Mul(GF128One))
+	testutil.AssertEqual(t, a.Mul(b), b.Mul(a))
+	testutil.AssertEqual(t, GF128One, a.Mul(a.Inv()))
+	testutil.AssertEqual(t, a, a.Square().Sqrt())
+	// x^128 = x^7 + x^2 + x + 1
+	x128 := GF128One
+	for i := 0; i < 128; i++ {
+		x128 = x128.Mul(GF128X)
+	}
+	testutil.AssertEqual(t, GF128{0xe1 << 56, 0}, x128)
+}
+
+func TestGFPoly(t *testing.T) {
+	rand := detrand.New(2)
+	src := random.New(rand)
+	r := []GF128{randomGF128(src), randomGF128(src), randomGF128(src)}
+	lin := func(a GF128) Poly { return Poly{a, GF128One} }
+
+	// (x+r0)^2 (x+r1) (x+r2)
+	f := lin(r[0]).Mul(lin(r[0])).Mul(lin(r[1])).Mul(lin(r[2]))
+	q, r2 := f.Mul(Poly{r[1], r[2]}).DivMod(Poly{r[1], r[2]})
+	testutil.AssertEqual(t, true, q.Equal(f))
+	testutil.AssertEqual(t, 0, len(r2))
+	testutil.AssertEqual(t, true, f.GCD(lin(r[1]).Mul(lin(r[0]))).Equal(lin(r[1]).Mul(lin(r[0])).Monic()))
+
+	sf := f.SquareFree()
+	testutil.AssertEqual(t, 2, len(sf))
+	testutil.AssertEqual(t, true, sf[0].F.Equal(lin(r[1]).Mul(lin(r[2]))))
+	testutil.AssertEqual(t, 1, sf[0].N)
+	testutil.AssertEqual(t, true, sf[1].F.Equal(lin(r[0])))
+	testutil.AssertEqual(t, 2, sf[1].N)
+
+	got := make(map[GF128]bool)
+	for _, root := range f.Roots(rand) {
+		got[root] = true
+		testutil.AssertEqual(t, true, f.Eval(root).IsZero())
+	}
+	testutil.AssertEqual(t, map[GF128]bool{r[0]: true, r[1]: true, r[2]: true}, got)
+
+	// x^2 + x + c is irreducible about half the time, and then it has no
+	// roots to add
+	for {
+		c := randomGF128(src)
+		g := Poly{c, GF128One, GF128One}
+		dd := g.DistinctDegree()
+		if len(dd) == 1 && dd[0].N == 2 {
+			testutil.AssertEqual(t, []GF128{r[0]}, g.Mul(lin(r[0])).Roots(rand))
+			break
+		}
+	}
+}
+
+func TestGCM(t *testing.T) {
+	src := random.New(detrand.New(1))
+	key := src.Key()
+	b, _ := aes.NewCipher(key)
+	ours := New(b)
+	theirs, _ := cipher.NewGCM(b)
+
+	for _, n := range []int{0, 1, 15, 16, 17, 100} {
+		nonce, pt, ad := make([]byte, NonceSize), make([]byte, n), make([]byte, n/3)
+		src.Fill(nonce)
+		src.Fill(pt)
+		src.Fill(ad)
+
+		sealed := ours.Seal(nonce, pt, ad)
+		testutil.AssertEqual(t, theirs.Seal(nil, nonce, pt, ad), sealed)
+
+		opened, err := ours.Open(nonce, sealed, ad)
+		testutil.AssertEqual(t, nil, err)
+		testutil.AssertEqual(t, true, bytes.Equal(pt, opened))
+
+		sealed[0] ^= 1
+		_, err = ours.Open(nonce, sealed, ad)
+		testutil.AssertEqual(t, true, err != nil)
+	}
+}
+
+func TestChallenge63(t *testing.T) {
+	rand := detrand.New(63)
+	src := random.New(rand)
+	b, _ := aes.NewCipher(src.Key())
+	g := New(b)
+	nonce := make([]byte, NonceSize)
+	src.Fill(nonce)
+
+	var msgs []Message
+	for _, s := range []string{
+		"transfer 100 to alice, and then transfer 50 to bob",
+		"transfer 200 to carol",
+		"cancel everything",
+	} {
+		ad := []byte("from: dave")
+		sealed := g.Seal(nonce, []byte(s), ad)
+		msgs = append(msgs, Message{ad, sealed[:len(s)], sealed[len(s):]})
+	}
+
+	keys := RecoverKey(rand, msgs)
+	testutil.AssertEqual(t, []GF128{g.h}, keys)
+
+	// CTR is malleable, and now we can fix up the tag too
+	want := []byte("transfer 999 to eve")
+	ct := xorcipher.XOR(xorcipher.XOR(msgs[1].CT, []byte("transfer 200 to carol")), want)
+	ad := []byte("from: frank")
+	forged := append(ct, ForgeTag(keys[0], msgs[0], ad, ct)...)
+	pt, err := g.Open(nonce, forged, ad)
+	testutil.AssertEqual(t, nil, err)
+	testutil.AssertEqual(t, string(want), string(pt))
+}
+
+func TestGF128Matrix(t *testing.T) {
+	// multiplication and squaring in GF(2^128) as matrices
+	src := random.New(detrand.New(3))
+	a, b := randomGF128(src), randomGF128(src)
+	testutil.AssertEqual(t, a.Mul(b), gf128FromVec(a.mulMatrix().MulVec(b.vec())))
+	testutil.AssertEqual(t, a.Square(), gf128FromVec(gf128SquareMatrix().MulVec(a.vec())))
+}
+
+func TestChallenge64(t *testing.T) {
+	rand := detrand.New(64)
+	src := random.New(rand)
+	b, _ := aes.NewCipher(src.Key())
+	g := NewWithTagSize(b, 2)
+	nonce := make([]byte, NonceSize)
+	src.Fill(nonce)
+
+	pt := make([]byte, 16<<8)
+	src.Fill(pt)
+	sealed := g.Seal(nonce, pt, nil)
+	ct, tag := sealed[:len(pt)], sealed[len(pt):]
+
+	oracle := func(ct, tag []byte) bool {
+		_, err := g.Open(nonce, append(append([]byte(nil), ct...), tag...), nil)
+		return err == nil
+	}
+	h, queries := NewTruncatedAttack(rand, ct, tag, oracle).RecoverKey()
+	testutil.AssertEqual(t, g.h, h)
+	t.Logf("%d forgery attempts", queries)
+}
@@ -0,0 +1,430 @@
+package gcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/gf2"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// GF128 is an element of GF(2^128), the field GCM works in: polynomials over
+// GF(2) modulo x^128 + x^7 + x^2 + x + 1. It uses GCM's own bit order, so
+// the coefficient of x^0 is the most significant bit of hi, and the
+// coefficient of x^127 is the least significant bit of lo. That's what you
+// get by reading a 16 byte block as two big-endian words.
+type GF128 struct {
+	hi, lo uint64
+}
+
+// GF128One is the multiplicative identity, the polynomial 1.
+var GF128One = GF128{1 << 63, 0}
+
+// GF128X is the polynomial x.
+var GF128X = GF128{1 << 62, 0}
+
+// gf128R is the low part of the reduction polynomial, x^7 + x^2 + x + 1,
+// which is what x^128 wraps around to.
+const gf128R = 0xe1 << 56
+
+// GF128FromBytes reads a 16 byte block as a field element.
+func GF128FromBytes(b []byte) GF128 {
+	return GF128{binary.BigEndian.Uint64(b), binary.BigEndian.Uint64(b[8:])}
+}
+
+// Bytes returns the 16 byte block representing a.
+func (a GF128) Bytes() []byte {
+	res := make([]byte, 16)
+	binary.BigEndian.PutUint64(res, a.hi)
+	binary.BigEndian.PutUint64(res[8:], a.lo)
+	return res
+}
+
+func (a GF128) String() string {
+	return fmt.Sprintf("%016x%016x", a.hi, a.lo)
+}
+
+// IsZero says whether a is the zero element.
+func (a GF128) IsZero() bool {
+	return a.hi == 0 && a.lo == 0
+}
+
+// Add returns a + b. Subtraction is the same thing.
+func (a GF128) Add(b GF128) GF128 {
+	return GF128{a.hi ^ b.hi, a.lo ^ b.lo}
+}
+
+// Mul returns a * b, one bit of a at a time: we add in b times each power of
+// x where a has a 1, multiplying b by x as we go. Multiplying by x is a
+// shift to the right in this bit order, and if x^127 falls off the end it
+// comes back as x^7 + x^2 + x + 1.
+func (a GF128) Mul(b GF128) GF128 {
+	var z GF128
+	v := b
+	for _, w := range [2]uint64{a.hi, a.lo} {
+		for i := 63; i >= 0; i-- {
+			if w>>uint(i)&1 == 1 {
+				z.hi ^= v.hi
+				z.lo ^= v.lo
+			}
+			carry := v.lo & 1
+			v.lo = v.lo>>1 | v.hi<<63
+			v.hi >>= 1
+			if carry == 1 {
+				v.hi ^= gf128R
+			}
+		}
+	}
+	return z
+}
+
+// Square returns a^2.
+func (a GF128) Square() GF128 {
+	return a.Mul(a)
+}
+
+// Inv returns the multiplicative inverse of a, which is a^(2^128 - 2). It
+// panics if a is zero.
+func (a GF128) Inv() GF128 {
+	if a.IsZero() {
+		panic("inv: zero has no inverse")
+	}
+	// 2^128 - 2 is 127 ones followed by a zero
+	res := GF128One
+	for i := 0; i < 127; i++ {
+		res = res.Mul(a).Square()
+	}
+	return res
+}
+
+// Sqrt returns the square root of a, a^(2^127). Squaring is a bijection in
+// characteristic 2, so every element has exactly one.
+func (a GF128) Sqrt() GF128 {
+	for i := 0; i < 127; i++ {
+		a = a.Square()
+	}
+	return a
+}
+
+// vec returns a as a vector over GF(2), with the coefficient of x^i in
+// position i. Multiplication by a constant and squaring are both linear
+// maps on these vectors.
+func (a GF128) vec() gf2.Vec {
+	v := gf2.NewVec(128)
+	v[0] = bits.Reverse64(a.hi)
+	v[1] = bits.Reverse64(a.lo)
+	return v
+}
+
+// gf128FromVec is the inverse of vec.
+func gf128FromVec(v gf2.Vec) GF128 {
+	return GF128{bits.Reverse64(v[0]), bits.Reverse64(v[1])}
+}
+
+// mulMatrix returns the matrix M with M*vec(b) = vec(a*b). Its columns are
+// a times each power of x.
+func (a GF128) mulMatrix() *gf2.Matrix {
+	cols := make([]gf2.Vec, 128)
+	xi := GF128One
+	for i := range cols {
+		cols[i] = a.Mul(xi).vec()
+		xi = xi.Mul(GF128X)
+	}
+	return gf2.FromColumns(128, cols)
+}
+
+// gf128SquareMatrix returns the matrix S with S*vec(a) = vec(a^2), which
+// exists because squaring is linear in characteristic 2.
+func gf128SquareMatrix() *gf2.Matrix {
+	cols := make([]gf2.Vec, 128)
+	xi := GF128One
+	for i := range cols {
+		cols[i] = xi.Square().vec()
+		xi = xi.Mul(GF128X)
+	}
+	return gf2.FromColumns(128, cols)
+}
+
+// randomGF128 returns a random field element.
+func randomGF128(src *random.Source) GF128 {
+	return GF128FromBytes(src.Key())
+}
+
+// Poly is a polynomial with coefficients in GF(2^128), lowest degree
+// first. The functions working with them always trim any zero leading
+// coefficients, so the zero polynomial is empty.
+type Poly []GF128
+
+// Trim drops zero leading coefficients.
+func (f Poly) Trim() Poly {
+	for len(f) > 0 && f[len(f)-1].IsZero() {
+		f = f[:len(f)-1]
+	}
+	return f
+}
+
+// Degree returns the degree of f, with -1 for the zero polynomial.
+func (f Poly) Degree() int {
+	return len(f.Trim()) - 1
+}
+
+// IsOne says whether f is the constant polynomial 1.
+func (f Poly) IsOne() bool {
+	f = f.Trim()
+	return len(f) == 1 && f[0] == GF128One
+}
+
+// Equal says whether f and g are the same polynomial, ignoring any zero
+// leading coefficients.
+func (f Poly) Equal(g Poly) bool {
+	f, g = f.Trim(), g.Trim()
+	if len(f) != len(g) {
+		return false
+	}
+	for i := range f {
+		if f[i] != g[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns f + g, which is also f - g.
+func (f Poly) Add(g Poly) Poly {
+	if len(f) < len(g) {
+		f, g = g, f
+	}
+	res := make(Poly, len(f))
+	copy(res, f)
+	for i, c := range g {
+		res[i] = res[i].Add(c)
+	}
+	return res.Trim()
+}
+
+// Mul returns f * g.
+func (f Poly) Mul(g Poly) Poly {
+	f, g = f.Trim(), g.Trim()
+	if len(f) == 0 || len(g) == 0 {
+		return nil
+	}
+	res := make(Poly, len(f)+len(g)-1)
+	for i, a := range f {
+		if a.IsZero() {
+			continue
+		}
+		for j, b := range g {
+			res[i+j] = res[i+j].Add(a.Mul(b))
+		}
+	}
+	return res.Trim()
+}
+
+// Scale returns f with every coefficient multiplied by c.
+func (f Poly) Scale(c GF128) Poly {
+	res := make(Poly, len(f))
+	for i, a := range f {
+		res[i] = a.Mul(c)
+	}
+	return res.Trim()
+}
+
+// Monic divides f by its leading coefficient.
+func (f Poly) Monic() Poly {
+	f = f.Trim()
+	if len(f) == 0 {
+		return f
+	}
+	return f.Scale(f[len(f)-1].Inv())
+}
+
+// DivMod returns q and r such that f = qg + r, where r has a smaller degree
+// than g. It panics if g is zero.
+func (f Poly) DivMod(g Poly) (q, r Poly) {
+	g = g.Trim()
+	if len(g) == 0 {
+		panic("divMod: division by zero")
+	}
+	r = append(Poly(nil), f.Trim()...)
+	if len(r) < len(g) {
+		return nil, r
+	}
+	q = make(Poly, len(r)-len(g)+1)
+	lead := g[len(g)-1].Inv()
+	for len(r) >= len(g) {
+		shift := len(r) - len(g)
+		c := r[len(r)-1].Mul(lead)
+		q[shift] = c
+		for i, b := range g {
+			r[shift+i] = r[shift+i].Add(b.Mul(c))
+		}
+		r = r.Trim()
+	}
+	return q.Trim(), r
+}
+
+// Mod returns f modulo g. It panics if g is zero.
+func (f Poly) Mod(g Poly) Poly {
+	_, r := f.DivMod(g)
+	return r
+}
+
+// GCD returns the monic greatest common divisor of f and g.
+func (f Poly) GCD(g Poly) Poly {
+	f, g = f.Trim(), g.Trim()
+	for len(g) > 0 {
+		f, g = g, f.Mod(g)
+	}
+	return f.Monic()
+}
+
+// Derivative returns f'. In characteristic 2, the terms of even degree
+// vanish, and the odd ones lose a power of x.
+func (f Poly) Derivative() Poly {
+	f = f.Trim()
+	if len(f) < 2 {
+		return nil
+	}
+	res := make(Poly, len(f)-1)
+	for i := 1; i < len(f); i += 2 {
+		res[i-1] = f[i]
+	}
+	return res.Trim()
+}
+
+// Sqrt returns g such that g^2 = f, for an f whose derivative is zero, so
+// that every term has even degree.
+func (f Poly) Sqrt() Poly {
+	f = f.Trim()
+	res := make(Poly, (len(f)+1)/2)
+	for i := 0; i < len(f); i += 2 {
+		res[i/2] = f[i].Sqrt()
+	}
+	return res.Trim()
+}
+
+// frobeniusMod returns f^(2^128) modulo m, by squaring 128 times. The
+// Frobenius map fixes GF(2^128), so this is how we get at x^q with
+// q = 2^128.
+func (f Poly) frobeniusMod(m Poly) Poly {
+	for i := 0; i < 128; i++ {
+		f = f.Mul(f).Mod(m)
+	}
+	return f
+}
+
+// Factor is a factor of a polynomial along with a number: its
+// multiplicity from SquareFree, or the degree of its irreducible factors
+// from DistinctDegree.
+type Factor struct {
+	F Poly
+	N int
+}
+
+// SquareFree splits the monic polynomial f into square-free factors, each
+// paired with how many times it divides f.
+func (f Poly) SquareFree() []Factor {
+	var res []Factor
+	c := f.GCD(f.Derivative())
+	w, _ := f.DivMod(c)
+	for i := 1; !w.IsOne(); i++ {
+		y := w.GCD(c)
+		fac, _ := w.DivMod(y)
+		if !fac.IsOne() {
+			res = append(res, Factor{fac, i})
+		}
+		w = y
+		c, _ = c.DivMod(y)
+	}
+	// whatever is left is a perfect square
+	if !c.IsOne() {
+		for _, fac := range c.Sqrt().SquareFree() {
+			res = append(res, Factor{fac.F, 2 * fac.N})
+		}
+	}
+	return res
+}
+
+// DistinctDegree splits the monic, square-free polynomial f into factors
+// which are each a product of irreducible polynomials of a single degree,
+// paired with that degree. The irreducible polynomials of degree d all
+// divide x^(q^d) - x.
+func (f Poly) DistinctDegree() []Factor {
+	var res []Factor
+	x := Poly{GF128{}, GF128One}
+	h := x.Mod(f)
+	for d := 1; 2*d <= f.Degree(); d++ {
+		h = h.frobeniusMod(f)
+		g := f.GCD(h.Add(x))
+		if !g.IsOne() {
+			res = append(res, Factor{g, d})
+			f, _ = f.DivMod(g)
+			h = h.Mod(f)
+		}
+	}
+	if f.Degree() > 0 {
+		res = append(res, Factor{f, f.Degree()})
+	}
+	return res
+}
+
+// EqualDegree splits f, a monic product of distinct irreducible polynomials
+// of degree d, into those polynomials. This is Cantor-Zassenhaus for
+// characteristic 2: for a random r, the trace r + r^2 + r^4 + ... +
+// r^(2^(128d-1)) is 0 or 1 modulo each factor, with even odds, so its gcd
+// with f usually splits f. The r are read from rand, or crypto/rand if it's
+// nil.
+func (f Poly) EqualDegree(rand io.Reader, d int) []Poly {
+	return f.equalDegree(random.New(rand), d)
+}
+
+func (f Poly) equalDegree(src *random.Source, d int) []Poly {
+	n := f.Degree()
+	if n <= d {
+		return []Poly{f}
+	}
+	for {
+		r := make(Poly, n)
+		for i := range r {
+			r[i] = randomGF128(src)
+		}
+		r = r.Trim()
+
+		t, sq := r, r
+		for i := 1; i < 128*d; i++ {
+			sq = sq.Mul(sq).Mod(f)
+			t = t.Add(sq)
+		}
+		g := f.GCD(t)
+		if g.Degree() <= 0 || g.Degree() == n {
+			continue
+		}
+		q, _ := f.DivMod(g)
+		return append(g.equalDegree(src, d), q.equalDegree(src, d)...)
+	}
+}
+
+// Roots returns the distinct roots of f in GF(2^128). Splitting f up takes
+// some randomness, which is read from rand, or crypto/rand if it's nil.
+func (f Poly) Roots(rand io.Reader) []GF128 {
+	src := random.New(rand)
+	var res []GF128
+	f = f.Monic()
+	if f.Degree() < 1 {
+		return nil
+	}
+	for _, sf := range f.SquareFree() {
+		for _, dd := range sf.F.DistinctDegree() {
+			if dd.N != 1 {
+				continue
+			}
+			// x + a has the root a
+			for _, lin := range dd.F.equalDegree(src, 1) {
+				res = append(res, lin[0])
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,239 @@
+// Package gf2 does linear algebra over GF(2), with vectors and matrices
+// packed into machine words.
+package gf2
+
+import "math/bits"
+
+// Vec is a vector over GF(2), packed 64 bits to a word with bit i in
+// word i/64 at position i%64.
+type Vec []uint64
+
+// NewVec returns the zero vector with n entries.
+func NewVec(n int) Vec {
+	return make(Vec, (n+63)/64)
+}
+
+// Bit returns entry i of v.
+func (v Vec) Bit(i int) bool {
+	return v[i/64]>>uint(i%64)&1 == 1
+}
+
+// SetBit sets entry i of v.
+func (v Vec) SetBit(i int, b bool) {
+	if b {
+		v[i/64] |= 1 << uint(i%64)
+	} else {
+		v[i/64] &^= 1 << uint(i%64)
+	}
+}
+
+// XORIn adds w to v in place.
+func (v Vec) XORIn(w Vec) {
+	for i := range v {
+		v[i] ^= w[i]
+	}
+}
+
+// Dot returns the inner product of v and w.
+func (v Vec) Dot(w Vec) bool {
+	var acc uint64
+	for i := range v {
+		acc ^= v[i] & w[i]
+	}
+	return bits.OnesCount64(acc)&1 == 1
+}
+
+// IsZero says whether every entry of v is 0.
+func (v Vec) IsZero() bool {
+	for _, w := range v {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a copy of v.
+func (v Vec) Clone() Vec {
+	return append(Vec(nil), v...)
+}
+
+// Matrix is a matrix over GF(2), stored as a slice of packed rows.
+type Matrix struct {
+	Rows, Cols int
+	data       []Vec
+}
+
+// NewMatrix returns the rows by cols zero matrix.
+func NewMatrix(rows, cols int) *Matrix {
+	m := &Matrix{Rows: rows, Cols: cols, data: make([]Vec, rows)}
+	for i := range m.data {
+		m.data[i] = NewVec(cols)
+	}
+	return m
+}
+
+// Identity returns the n by n identity matrix.
+func Identity(n int) *Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, true)
+	}
+	return m
+}
+
+// FromColumns returns the matrix with the given columns, which must all
+// have n entries.
+func FromColumns(n int, cols []Vec) *Matrix {
+	m := NewMatrix(n, len(cols))
+	for j, c := range cols {
+		for i := 0; i < n; i++ {
+			m.Set(i, j, c.Bit(i))
+		}
+	}
+	return m
+}
+
+// Row returns row i of m. It shares its storage with m, so changing it
+// changes m.
+func (m *Matrix) Row(i int) Vec {
+	return m.data[i]
+}
+
+// Get returns the entry in row i and column j.
+func (m *Matrix) Get(i, j int) bool {
+	return m.data[i].Bit(j)
+}
+
+// Set sets the entry in row i and column j.
+func (m *Matrix) Set(i, j int, b bool) {
+	m.data[i].SetBit(j, b)
+}
+
+// AppendRow adds v to the bottom of m.
+func (m *Matrix) AppendRow(v Vec) {
+	m.data = append(m.data, v.Clone())
+	m.Rows++
+}
+
+// Clone returns a copy of m.
+func (m *Matrix) Clone() *Matrix {
+	res := &Matrix{Rows: m.Rows, Cols: m.Cols, data: make([]Vec, m.Rows)}
+	for i, row := range m.data {
+		res.data[i] = row.Clone()
+	}
+	return res
+}
+
+// Add returns m + n.
+func (m *Matrix) Add(n *Matrix) *Matrix {
+	if m.Rows != n.Rows || m.Cols != n.Cols {
+		panic("add: matrix sizes don't match")
+	}
+	res := m.Clone()
+	for i, row := range res.data {
+		row.XORIn(n.data[i])
+	}
+	return res
+}
+
+// Mul returns the product mn. Each row of the result is the sum of the rows
+// of n picked out by the corresponding row of m.
+func (m *Matrix) Mul(n *Matrix) *Matrix {
+	if m.Cols != n.Rows {
+		panic("mul: matrix sizes don't match")
+	}
+	res := NewMatrix(m.Rows, n.Cols)
+	for i, row := range m.data {
+		for j := 0; j < m.Cols; j++ {
+			if row.Bit(j) {
+				res.data[i].XORIn(n.data[j])
+			}
+		}
+	}
+	return res
+}
+
+// MulVec returns the product mv.
+func (m *Matrix) MulVec(v Vec) Vec {
+	res := NewVec(m.Rows)
+	for i, row := range m.data {
+		res.SetBit(i, row.Dot(v))
+	}
+	return res
+}
+
+// Transpose returns the transpose of m.
+func (m *Matrix) Transpose() *Matrix {
+	res := NewMatrix(m.Cols, m.Rows)
+	for i, row := range m.data {
+		for j := 0; j < m.Cols; j++ {
+			if row.Bit(j) {
+				res.Set(j, i, true)
+			}
+		}
+	}
+	return res
+}
+
+// RowReduce returns the reduced row echelon form of m, and the column of
+// the leading 1 in each of its nonzero rows.
+func (m *Matrix) RowReduce() (*Matrix, []int) {
+	res := m.Clone()
+	var pivots []int
+	r := 0
+	for c := 0; c < res.Cols && r < res.Rows; c++ {
+		p := -1
+		for i := r; i < res.Rows; i++ {
+			if res.Get(i, c) {
+				p = i
+				break
+			}
+		}
+		if p < 0 {
+			continue
+		}
+		res.data[r], res.data[p] = res.data[p], res.data[r]
+		for i := 0; i < res.Rows; i++ {
+			if i != r && res.Get(i, c) {
+				res.data[i].XORIn(res.data[r])
+			}
+		}
+		pivots = append(pivots, c)
+		r++
+	}
+	return res, pivots
+}
+
+// Rank returns the rank of m.
+func (m *Matrix) Rank() int {
+	_, pivots := m.RowReduce()
+	return len(pivots)
+}
+
+// Kernel returns a basis for the vectors v with mv = 0. Each column
+// without a pivot in the reduced form gives one: set that variable to 1,
+// the other free ones to 0, and the pivot variables follow.
+func (m *Matrix) Kernel() []Vec {
+	reduced, pivots := m.RowReduce()
+	isPivot := make([]bool, m.Cols)
+	for _, c := range pivots {
+		isPivot[c] = true
+	}
+
+	var res []Vec
+	for free := 0; free < m.Cols; free++ {
+		if isPivot[free] {
+			continue
+		}
+		v := NewVec(m.Cols)
+		v.SetBit(free, true)
+		for r, c := range pivots {
+			if reduced.Get(r, free) {
+				v.SetBit(c, true)
+			}
+		}
+		res = append(res, v)
+	}
+	return res
+}
@@ -0,0 +1,42 @@
+package gf2
+
+import (
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestMatrix(t *testing.T) {
+	// x + y = 0, y + z = 0 has kernel spanned by (1, 1, 1)
+	m := NewMatrix(2, 3)
+	m.Set(0, 0, true)
+	m.Set(0, 1, true)
+	m.Set(1, 1, true)
+	m.Set(1, 2, true)
+	testutil.AssertEqual(t, 2, m.Rank())
+	kernel := m.Kernel()
+	testutil.AssertEqual(t, 1, len(kernel))
+	for i := 0; i < 3; i++ {
+		testutil.AssertEqual(t, true, kernel[0].Bit(i))
+	}
+	testutil.AssertEqual(t, true, m.MulVec(kernel[0]).IsZero())
+
+	id := Identity(3)
+	testutil.AssertEqual(t, m.data, m.Mul(id).data)
+	testutil.AssertEqual(t, m.data, m.Transpose().Transpose().data)
+	testutil.AssertEqual(t, 0, len(id.Kernel()))
+
+	// a random wide matrix has a kernel of the expected size
+	src := random.New(detrand.New(1))
+	r := NewMatrix(64, 100)
+	for i := range r.data {
+		r.data[i][0] = uint64(src.Int(1<<62)) << 1
+		r.data[i][1] = uint64(src.Int(1 << 36))
+	}
+	for _, v := range r.Kernel() {
+		testutil.AssertEqual(t, true, r.MulVec(v).IsZero())
+	}
+	testutil.AssertEqual(t, 100-r.Rank(), len(r.Kernel()))
+}
@@ -0,0 +1,3 @@
+module github.com/jabley/matasano-crypto-challenges/golang
+
+go 1.21
@@ -0,0 +1,13 @@
+// Package bigint holds helpers for writing down big numbers.
+package bigint
+
+import "math/big"
+
+// MustParse parses a decimal number, and panics if it can't.
+func MustParse(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("bigint.MustParse: bad number " + s)
+	}
+	return n
+}
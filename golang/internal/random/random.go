@@ -0,0 +1,115 @@
+// Package random provides the randomness which keys, IVs and oracles need.
+//
+// A Source reads it from an io.Reader, so that the oracles, key generators
+// and attacks can take one and be made to do exactly the same thing again,
+// given something like detrand's deterministic reader, which is what the
+// tests use.
+package random
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"math/big"
+)
+
+// Source hands out random keys, IVs and numbers, read from an io.Reader.
+type Source struct {
+	r io.Reader
+}
+
+// New returns a Source reading from r, or from crypto/rand if r is nil.
+func New(r io.Reader) *Source {
+	if r == nil {
+		r = rand.Reader
+	}
+	return &Source{r: r}
+}
+
+// Key generates a new random key of 16 bytes which can be used to encrypt
+// content.
+func (s *Source) Key() []byte {
+	res := make([]byte, 16)
+	s.Fill(res)
+	return res
+}
+
+// IV generates a new random IV of 16 bytes.
+func (s *Source) IV() []byte {
+	return s.Key()
+}
+
+// Bytes returns a random array of up to c bytes.
+func (s *Source) Bytes(c int) []byte {
+	n := s.Int(c)
+	res := make([]byte, n)
+	s.Fill(res)
+	return res
+}
+
+// Fill fills dst with random bytes. It panics if the reader fails, since
+// there's nothing sensible to do without randomness.
+func (s *Source) Fill(dst []byte) {
+	if _, err := io.ReadFull(s.r, dst); err != nil {
+		panic(err)
+	}
+}
+
+// Split returns a new Source, seeded from s, for a goroutine to use
+// without having to share s. Splitting the same s in the same order gives
+// the same new Sources, however the goroutines end up being scheduled. The
+// new Source is AES-CTR, keyed from s.
+func (s *Source) Split() *Source {
+	b, err := aes.NewCipher(s.Key())
+	if err != nil {
+		panic(err)
+	}
+	return New(cipher.StreamReader{S: cipher.NewCTR(b, make([]byte, aes.BlockSize)), R: zeros{}})
+}
+
+// zeros reads as an endless run of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// Int returns, as an int, a non-negative random number in [0,n). It
+// panics if n <= 0
+func (s *Source) Int(n int) int {
+	return int(s.BigInt(big.NewInt(int64(n))).Int64())
+}
+
+// BigInt returns a uniform random number in [0,n). It panics if n <= 0
+func (s *Source) BigInt(n *big.Int) *big.Int {
+	i, err := rand.Int(s.r, n)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// Prime returns a random prime of exactly the given number of bits. Unlike
+// crypto/rand.Prime, which only uses the reader it's given if GODEBUG says
+// so, it's the same every time for the same reader.
+func (s *Source) Prime(bits int) *big.Int {
+	if bits < 3 {
+		panic("random: prime size must be at least 3 bits")
+	}
+	buf := make([]byte, (bits+7)/8)
+	top := uint(bits-1) % 8
+	p := new(big.Int)
+	for {
+		s.Fill(buf)
+		// Trim to size, set the top bit so that it's exactly that size,
+		// and the bottom one so that it's odd.
+		buf[0] &= byte(1<<(top+1) - 1)
+		buf[0] |= 1 << top
+		buf[len(buf)-1] |= 1
+		if p.SetBytes(buf).ProbablyPrime(20) {
+			return p
+		}
+	}
+}
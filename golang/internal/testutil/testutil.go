@@ -0,0 +1,75 @@
+// Package testutil holds the assertions and fixtures shared by the tests.
+package testutil
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// AssertEqual fails the test unless expected and actual are deeply equal.
+func AssertEqual(t testing.TB, expected, actual interface{}) {
+	t.Helper()
+	if expected == nil || actual == nil {
+
+		if actual != expected {
+			fail(t, expected, actual)
+		}
+		return
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		fail(t, expected, actual)
+	}
+}
+
+// DecodeBase64 decodes s, failing the test if it can't.
+func DecodeBase64(t testing.TB, s string) []byte {
+	t.Helper()
+	v, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal("failed to decode base64:", s)
+	}
+	return v
+}
+
+// EncodeBase64 encodes in using standard base64.
+func EncodeBase64(in []byte) string {
+	return base64.StdEncoding.EncodeToString(in)
+}
+
+// DecodeHex decodes s, failing the test if it can't.
+func DecodeHex(t testing.TB, s string) []byte {
+	t.Helper()
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal("failed to decode hex:", s)
+	}
+	return v
+}
+
+func fail(t testing.TB, expected, actual interface{}) {
+	t.Helper()
+	t.Fatalf("Expected\n%#v\nActual:\n%#v\n", expected, actual)
+}
+
+// FatalIfErr stops the test if err isn't nil.
+func FatalIfErr(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ReadFile returns the contents of the named file, failing the test if it
+// can't be read. The challenge inputs and outputs live in the inputs and
+// outputs directories at the top of the repository.
+func ReadFile(t testing.TB, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal("failed to read file:", err)
+	}
+	return data
+}
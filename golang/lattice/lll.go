@@ -0,0 +1,126 @@
+// Package lattice reduces lattice bases, which is the tool behind a lot of
+// attacks on signatures with partially known nonces.
+package lattice
+
+import "math/big"
+
+// Delta is the usual choice for how much each Gram-Schmidt vector may
+// shrink relative to the one before it.
+var Delta = big.NewRat(99, 100)
+
+// dot returns the inner product of two vectors.
+func dot(u, v []*big.Rat) *big.Rat {
+	res, t := new(big.Rat), new(big.Rat)
+	for i := range u {
+		res.Add(res, t.Mul(u[i], v[i]))
+	}
+	return res
+}
+
+// roundRat rounds x to the nearest integer, with halves going up.
+func roundRat(x *big.Rat) *big.Int {
+	num := new(big.Int).Lsh(x.Num(), 1)
+	num.Add(num, x.Denom())
+	return num.Div(num, new(big.Int).Lsh(x.Denom(), 1))
+}
+
+// LLL reduces the lattice basis given by the rows of basis, which must be
+// linearly independent, using the Lenstra-Lenstra-Lovász algorithm with the
+// given delta, which should be in (1/4, 1). The result spans the same
+// lattice but its vectors are short and nearly orthogonal; in particular the
+// first one is within a factor of 2^((n-1)/2) of the shortest vector in the
+// lattice, and usually much closer than that. basis is left alone.
+//
+// Rather than recomputing the Gram-Schmidt orthogonalisation every time the
+// basis changes, we keep the coefficients mu and the squared norms of the
+// orthogonal vectors up to date as we go, which is much faster.
+func LLL(basis [][]*big.Rat, delta *big.Rat) [][]*big.Rat {
+	n := len(basis)
+	b := make([][]*big.Rat, n)
+	for i, row := range basis {
+		b[i] = make([]*big.Rat, len(row))
+		for j, x := range row {
+			b[i][j] = new(big.Rat).Set(x)
+		}
+	}
+	if n == 0 {
+		return b
+	}
+
+	// the Gram-Schmidt orthogonalisation: b*_i = b_i - sum mu[i][j] b*_j,
+	// and norms[i] = <b*_i, b*_i>
+	mu := make([][]*big.Rat, n)
+	norms := make([]*big.Rat, n)
+	star := make([][]*big.Rat, n)
+	t := new(big.Rat)
+	for i := range b {
+		mu[i] = make([]*big.Rat, n)
+		star[i] = make([]*big.Rat, len(b[i]))
+		for k, x := range b[i] {
+			star[i][k] = new(big.Rat).Set(x)
+		}
+		for j := 0; j < i; j++ {
+			mu[i][j] = dot(b[i], star[j])
+			mu[i][j].Quo(mu[i][j], norms[j])
+			for k := range star[i] {
+				star[i][k].Sub(star[i][k], t.Mul(mu[i][j], star[j][k]))
+			}
+		}
+		norms[i] = dot(star[i], star[i])
+	}
+
+	half := big.NewRat(1, 2)
+	q := new(big.Rat)
+	for k := 1; k < n; {
+		// size reduction: make |mu[k][j]| <= 1/2 for all j < k
+		for j := k - 1; j >= 0; j-- {
+			if t.Abs(mu[k][j]).Cmp(half) <= 0 {
+				continue
+			}
+			q.SetInt(roundRat(mu[k][j]))
+			for i := range b[k] {
+				b[k][i].Sub(b[k][i], t.Mul(q, b[j][i]))
+			}
+			mu[k][j].Sub(mu[k][j], q)
+			for i := 0; i < j; i++ {
+				mu[k][i].Sub(mu[k][i], t.Mul(q, mu[j][i]))
+			}
+		}
+
+		// the Lovász condition: norms[k] >= (delta - mu[k][k-1]^2) norms[k-1]
+		m := mu[k][k-1]
+		bound := new(big.Rat).Mul(m, m)
+		bound.Sub(delta, bound)
+		bound.Mul(bound, norms[k-1])
+		if norms[k].Cmp(bound) >= 0 {
+			k++
+			continue
+		}
+
+		// swap b_k and b_k-1, and fix up the orthogonalisation
+		b[k], b[k-1] = b[k-1], b[k]
+		for j := 0; j < k-1; j++ {
+			mu[k][j], mu[k-1][j] = mu[k-1][j], mu[k][j]
+		}
+		m = new(big.Rat).Set(m)
+		newNorm := new(big.Rat).Mul(m, m)
+		newNorm.Mul(newNorm, norms[k-1])
+		newNorm.Add(newNorm, norms[k])
+		mu[k][k-1] = new(big.Rat).Mul(m, norms[k-1])
+		mu[k][k-1].Quo(mu[k][k-1], newNorm)
+		norms[k] = new(big.Rat).Mul(norms[k], norms[k-1])
+		norms[k].Quo(norms[k], newNorm)
+		norms[k-1] = newNorm
+		for i := k + 1; i < n; i++ {
+			old := mu[i][k]
+			mu[i][k] = new(big.Rat).Mul(m, old)
+			mu[i][k].Sub(mu[i][k-1], mu[i][k])
+			mu[i][k-1] = new(big.Rat).Mul(mu[k][k-1], mu[i][k])
+			mu[i][k-1].Add(mu[i][k-1], old)
+		}
+		if k > 1 {
+			k--
+		}
+	}
+	return b
+}
@@ -0,0 +1,66 @@
+package lattice
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func ratMatrix(rows ...[]string) [][]*big.Rat {
+	res := make([][]*big.Rat, len(rows))
+	for i, row := range rows {
+		for _, s := range row {
+			x, ok := new(big.Rat).SetString(s)
+			if !ok {
+				panic(s)
+			}
+			res[i] = append(res[i], x)
+		}
+	}
+	return res
+}
+
+func TestLLL(t *testing.T) {
+	basis := ratMatrix(
+		[]string{"-2", "0", "2", "0"},
+		[]string{"1/2", "-1", "0", "0"},
+		[]string{"-1", "0", "-2", "1/2"},
+		[]string{"-1", "1", "1", "2"},
+	)
+	want := ratMatrix(
+		[]string{"1/2", "-1", "0", "0"},
+		[]string{"-1", "0", "-2", "1/2"},
+		[]string{"-1/2", "0", "1", "2"},
+		[]string{"-3/2", "-1", "2", "0"},
+	)
+	got := LLL(basis, Delta)
+	testutil.AssertEqual(t, fmt.Sprint(want), fmt.Sprint(got))
+	// the input is left alone
+	testutil.AssertEqual(t, "-2", basis[0][0].RatString())
+
+	// an easy knapsack: the short vector picks out which weights sum to 42
+	weights := []int64{7, 12, 19, 23, 31}
+	var rows [][]string
+	for i, w := range weights {
+		row := []string{"0", "0", "0", "0", "0", fmt.Sprint(1000 * w)}
+		row[i] = "1"
+		rows = append(rows, row)
+	}
+	rows = append(rows, []string{"0", "0", "0", "0", "0", "-42000"})
+	found := false
+	for _, row := range LLL(ratMatrix(rows...), Delta) {
+		if row[5].Sign() != 0 {
+			continue
+		}
+		sum := new(big.Rat)
+		for i, w := range weights {
+			sum.Add(sum, new(big.Rat).Mul(row[i], big.NewRat(w, 1)))
+		}
+		if sum.Abs(sum).Cmp(big.NewRat(42, 1)) == 0 {
+			found = true
+		}
+	}
+	testutil.AssertEqual(t, true, found)
+}
@@ -0,0 +1,242 @@
+package md4
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// conditionKind says how a bit of a chaining variable is constrained.
+type conditionKind int
+
+const (
+	bitZero     conditionKind = iota // the bit is 0
+	bitOne                           // the bit is 1
+	bitEqual                         // the bit equals the same bit of another variable
+	bitNotEqual                      // the bit differs from the same bit of another variable
+)
+
+// condition is one of the sufficient conditions from Wang et al. for
+// the MD4 collision differential to hold.
+type condition struct {
+	v    int // the index of the variable, as returned by steps
+	bit  uint
+	kind conditionKind
+	ref  int // the index of the other variable, for bitEqual and bitNotEqual
+}
+
+// conditionTable is Table 6 from "Cryptanalysis of the Hash Functions MD4
+// and RIPEMD", keeping its notation, so that the two can be compared
+// side by side. Bits are numbered from 1. We stop at the end of round 2,
+// since message modification can't help with round 3.
+var conditionTable = []string{
+	"a1,7 = b0,7",
+	"d1,7 = 0", "d1,8 = a1,8", "d1,11 = a1,11",
+	"c1,7 = 1", "c1,8 = 1", "c1,11 = 0", "c1,26 = d1,26",
+	"b1,7 = 1", "b1,8 = 0", "b1,11 = 0", "b1,26 = 0",
+	"a2,8 = 1", "a2,11 = 1", "a2,26 = 0", "a2,14 = b1,14",
+	"d2,14 = 0", "d2,19 = a2,19", "d2,20 = a2,20", "d2,21 = a2,21", "d2,22 = a2,22", "d2,26 = 1",
+	"c2,13 = d2,13", "c2,14 = 0", "c2,15 = d2,15", "c2,19 = 0", "c2,20 = 0", "c2,21 = 1", "c2,22 = 0",
+	"b2,13 = 1", "b2,14 = 1", "b2,15 = 0", "b2,17 = c2,17", "b2,19 = 0", "b2,20 = 0", "b2,21 = 0", "b2,22 = 0",
+	"a3,13 = 1", "a3,14 = 1", "a3,15 = 1", "a3,17 = 0", "a3,19 = 0", "a3,20 = 0", "a3,21 = 0", "a3,23 = b2,23", "a3,22 = 1", "a3,26 = b2,26",
+	"d3,13 = 1", "d3,14 = 1", "d3,15 = 1", "d3,17 = 0", "d3,20 = 0", "d3,21 = 1", "d3,22 = 1", "d3,23 = 0", "d3,26 = 1", "d3,30 = a3,30",
+	"c3,17 = 1", "c3,20 = 0", "c3,21 = 0", "c3,22 = 0", "c3,23 = 0", "c3,26 = 0", "c3,30 = 1", "c3,32 = d3,32",
+	"b3,20 = 0", "b3,21 = 1", "b3,22 = 1", "b3,23 = c3,23", "b3,26 = 1", "b3,30 = 0", "b3,32 = 0",
+	"a4,23 = 0", "a4,26 = 0", "a4,27 = b3,27", "a4,29 = b3,29", "a4,30 = 1", "a4,32 = 0",
+	"d4,23 = 0", "d4,26 = 0", "d4,27 = 1", "d4,29 = 1", "d4,30 = 0", "d4,32 = 1",
+	"c4,19 = d4,19", "c4,23 = 1", "c4,26 = 1", "c4,27 = 0", "c4,29 = 0", "c4,30 = 0",
+	"b4,19 = 0", "b4,26 = c4,26", "b4,27 = 1", "b4,29 = 1", "b4,30 = 0",
+	"a5,19 = c4,19", "a5,26 = 1", "a5,27 = 0", "a5,29 = 1", "a5,32 = 1",
+	"d5,19 = a5,19", "d5,26 = b4,26", "d5,27 = b4,27", "d5,29 = b4,29", "d5,32 = b4,32",
+	"c5,26 = d5,26", "c5,27 = d5,27", "c5,29 = d5,29", "c5,30 = d5,30", "c5,32 = d5,32",
+	"b5,29 = c5,29", "b5,30 = 1", "b5,32 = 0",
+	"a6,29 = 1", "a6,32 = 1",
+	"d6,29 = b5,29",
+	"c6,29 = d6,29", "c6,30 != d6,30", "c6,32 != d6,32",
+}
+
+// conditions is conditionTable parsed, in the order the variables are
+// computed.
+var conditions = parseConditions(conditionTable)
+
+// parseConditions parses conditions like "a1,7 = b0,7" or "d1,7 = 0".
+func parseConditions(table []string) []condition {
+	var res []condition
+	for _, s := range table {
+		var name, rhs, op string
+		var c condition
+		if _, err := fmt.Sscanf(strings.Replace(s, ",", " ", -1), "%s %d %s %s", &name, &c.bit, &op, &rhs); err != nil {
+			panic(fmt.Sprintf("bad condition %q: %v", s, err))
+		}
+		c.v = varIndex(name)
+		switch {
+		case op == "=" && rhs == "0":
+			c.kind = bitZero
+		case op == "=" && rhs == "1":
+			c.kind = bitOne
+		case op == "=" || op == "!=":
+			c.kind = bitEqual
+			if op == "!=" {
+				c.kind = bitNotEqual
+			}
+			c.ref = varIndex(rhs)
+		default:
+			panic(fmt.Sprintf("bad condition %q", s))
+		}
+		res = append(res, c)
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].v < res[j].v })
+	return res
+}
+
+// varIndex turns a variable name like "d5" into its index in the
+// sequence returned by steps.
+func varIndex(name string) int {
+	var n int
+	if _, err := fmt.Sscanf(name[1:], "%d", &n); err != nil {
+		panic(fmt.Sprintf("bad variable %q: %v", name, err))
+	}
+	i := strings.IndexByte("adcb", name[0])
+	if i < 0 {
+		panic(fmt.Sprintf("bad variable %q", name))
+	}
+	return 4*n + i
+}
+
+// holds says whether the condition is met by the chaining variables v.
+func (c condition) holds(v []uint32) bool {
+	mask := uint32(1) << (c.bit - 1)
+	switch c.kind {
+	case bitZero:
+		return v[c.v]&mask == 0
+	case bitOne:
+		return v[c.v]&mask != 0
+	case bitEqual:
+		return v[c.v]&mask == v[c.ref]&mask
+	default:
+		return v[c.v]&mask != v[c.ref]&mask
+	}
+}
+
+// apply changes the chaining variables v so that the condition is met.
+func (c condition) apply(v []uint32) {
+	mask := uint32(1) << (c.bit - 1)
+	switch c.kind {
+	case bitZero:
+		v[c.v] &^= mask
+	case bitOne:
+		v[c.v] |= mask
+	case bitEqual:
+		v[c.v] = v[c.v]&^mask | v[c.ref]&mask
+	default:
+		v[c.v] = v[c.v]&^mask | ^v[c.ref]&mask
+	}
+}
+
+// conditionsHeld counts how many conditions on the variables up to and
+// including index last are met by v, and whether all of them are.
+func conditionsHeld(v []uint32, last int) (n int, all bool) {
+	all = true
+	for _, c := range conditions {
+		if c.v > last {
+			break
+		}
+		if c.holds(v) {
+			n++
+		} else {
+			all = false
+		}
+	}
+	return
+}
+
+// modifyRound1 does single-step message modification. Each variable in
+// round 1 depends on a message word of its own, so we can compute it, set
+// it to whatever the conditions need, and work out the message word which
+// gives that value instead.
+func modifyRound1(m *[16]uint32) {
+	v := steps(iv, m)
+	for i := 0; i < 16; i++ {
+		v[i+4] = step(v, m, i)
+		for _, c := range conditions {
+			if c.v == i+4 {
+				c.apply(v)
+			}
+		}
+		m[i] = invertStep(v, v[i+4], i)
+	}
+}
+
+// modifyRound2 does multi-step message modification for the first few
+// variables of round 2. Each of them reuses a message word from round 1, so
+// fixing one up changes a round 1 variable too. We then change the next
+// four message words so that the rest of round 1 comes out as it was.
+// Sometimes the change breaks one of the round 1 conditions, so we only keep
+// changes which leave us better off.
+func modifyRound2(m *[16]uint32) {
+	for i := 16; i < 20; i++ {
+		k := words[1][i%16]
+		if k+4 >= 16 {
+			// fixing this up would spill over into round 2
+			break
+		}
+
+		for _, c := range conditions {
+			if c.v != i+4 {
+				continue
+			}
+
+			v := steps(iv, m)
+			if c.holds(v) {
+				continue
+			}
+			before, _ := conditionsHeld(v, i+4)
+
+			modified := *m
+			c.apply(v)
+			modified[k] = invertStep(v, v[i+4], i)
+			v[k+4] = step(v, &modified, k)
+			for j := k + 1; j <= k+4; j++ {
+				modified[j] = invertStep(v, v[j+4], j)
+			}
+
+			after, _ := conditionsHeld(steps(iv, &modified), i+4)
+			if _, round1 := conditionsHeld(steps(iv, &modified), 19); round1 && after > before {
+				*m = modified
+			}
+		}
+	}
+}
+
+// differential returns the message which Wang et al.'s differential pairs
+// with m.
+func differential(m *[16]uint32) *[16]uint32 {
+	res := *m
+	res[1] += 1 << 31
+	res[2] += 1<<31 - 1<<28
+	res[12] -= 1 << 16
+	return &res
+}
+
+// FindCollision finds two different single block messages with the same
+// MD4 digest. We pick random messages, massage them so that most of the
+// conditions for the differential hold, and hope that the rest do too. The
+// messages are read from rand, or crypto/rand if it's nil.
+func FindCollision(rand io.Reader) (a, b []byte) {
+	src := random.New(rand)
+	for {
+		buf := make([]byte, BlockSize)
+		src.Fill(buf)
+		m := block(buf)
+		modifyRound1(m)
+		modifyRound2(m)
+
+		m2 := differential(m)
+		if compress(iv, m) == compress(iv, m2) {
+			return toBytes(m), toBytes(m2)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package md4 implements MD4 by hand, along with Wang et al.'s collision
+// attack on it.
+package md4
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// BlockSize is the size of an MD4 message block in bytes.
+const BlockSize = 64
+
+// iv is the initial MD4 chaining state, in the order a, b, c, d.
+var iv = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// shifts are the rotations used by each step of each round.
+var shifts = [3][4]int{
+	{3, 7, 11, 19},
+	{3, 5, 9, 13},
+	{3, 9, 11, 15},
+}
+
+// words is the order in which each round reads the message words.
+var words = [3][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15},
+	{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15},
+}
+
+// constants are added in by each round.
+var constants = [3]uint32{0, 0x5a827999, 0x6ed9eba1}
+
+func roundF(x, y, z uint32) uint32 { return x&y | ^x&z }
+func roundG(x, y, z uint32) uint32 { return x&y | x&z | y&z }
+func roundH(x, y, z uint32) uint32 { return x ^ y ^ z }
+
+var functions = [3]func(x, y, z uint32) uint32{roundF, roundG, roundH}
+
+// step computes the output of step i of the compression function. v is
+// the sequence of chaining variables, as described for steps, and needs
+// to hold at least the four values before step i.
+func step(v []uint32, m *[16]uint32, i int) uint32 {
+	round := i / 16
+	x := v[i] + functions[round](v[i+3], v[i+2], v[i+1]) + m[words[round][i%16]] + constants[round]
+	return bits.RotateLeft32(x, shifts[round][i%4])
+}
+
+// invertStep returns the message word which makes step i output out.
+func invertStep(v []uint32, out uint32, i int) uint32 {
+	round := i / 16
+	x := bits.RotateLeft32(out, -shifts[round][i%4])
+	return x - v[i] - functions[round](v[i+3], v[i+2], v[i+1]) - constants[round]
+}
+
+// steps returns every chaining variable the compression function goes
+// through, which is what differential attacks need to look at. In the
+// notation of Wang et al. that's a0, d0, c0, b0, a1, d1, c1, b1, ... b12,
+// so the output of step i is at index i+4.
+func steps(state [4]uint32, m *[16]uint32) []uint32 {
+	v := make([]uint32, 4, 52)
+	v[0], v[1], v[2], v[3] = state[0], state[3], state[2], state[1]
+	for i := 0; i < 48; i++ {
+		v = append(v, step(v, m, i))
+	}
+	return v
+}
+
+// compress runs the compression function over a single block.
+func compress(state [4]uint32, m *[16]uint32) [4]uint32 {
+	v := steps(state, m)
+	return [4]uint32{
+		state[0] + v[48],
+		state[1] + v[51],
+		state[2] + v[50],
+		state[3] + v[49],
+	}
+}
+
+// block decodes a 64 byte message block into little-endian words.
+func block(buf []byte) *[16]uint32 {
+	var m [16]uint32
+	for i := range m {
+		m[i] = binary.LittleEndian.Uint32(buf[4*i:])
+	}
+	return &m
+}
+
+// toBytes encodes a block of words back into bytes.
+func toBytes(m *[16]uint32) []byte {
+	res := make([]byte, BlockSize)
+	for i, w := range m {
+		binary.LittleEndian.PutUint32(res[4*i:], w)
+	}
+	return res
+}
+
+// pad pads msg to a multiple of the block size: a 1 bit, enough 0 bits
+// to leave room for the length, and the length of msg in bits.
+func pad(msg []byte) []byte {
+	n := len(msg) + 1 + 8
+	n += (BlockSize - n%BlockSize) % BlockSize
+
+	res := make([]byte, n)
+	copy(res, msg)
+	res[len(msg)] = 0x80
+	binary.LittleEndian.PutUint64(res[n-8:], uint64(len(msg))*8)
+	return res
+}
+
+// Sum returns the MD4 digest of msg.
+func Sum(msg []byte) []byte {
+	state := iv
+	padded := pad(msg)
+	for i := 0; i < len(padded); i += BlockSize {
+		state = compress(state, block(padded[i:]))
+	}
+
+	res := make([]byte, 16)
+	for i, w := range state {
+		binary.LittleEndian.PutUint32(res[4*i:], w)
+	}
+	return res
+}
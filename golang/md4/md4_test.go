@@ -0,0 +1,59 @@
+package md4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+
+	for _, test := range tests {
+		testutil.AssertEqual(t, test.expected, hex.EncodeToString(Sum([]byte(test.in))))
+	}
+}
+
+func TestConditions(t *testing.T) {
+	testutil.AssertEqual(t, 4, varIndex("a1"))
+	testutil.AssertEqual(t, 3, varIndex("b0"))
+	testutil.AssertEqual(t, 25, varIndex("d6"))
+	testutil.AssertEqual(t, len(conditionTable), len(conditions))
+	testutil.AssertEqual(t, condition{v: 5, bit: 8, kind: bitEqual, ref: 4}, parseConditions([]string{"d1,8 = a1,8"})[0])
+	testutil.AssertEqual(t, condition{v: 26, bit: 32, kind: bitNotEqual, ref: 25}, parseConditions([]string{"c6,32 != d6,32"})[0])
+
+	// single-step modification should satisfy every condition in round 1
+	src := random.New(detrand.New(1))
+	for i := 0; i < 10; i++ {
+		buf := make([]byte, BlockSize)
+		src.Fill(buf)
+		m := block(buf)
+		modifyRound1(m)
+		_, all := conditionsHeld(steps(iv, m), varIndex("b4"))
+		testutil.AssertEqual(t, true, all)
+
+		modifyRound2(m)
+		_, all = conditionsHeld(steps(iv, m), varIndex("b4"))
+		testutil.AssertEqual(t, true, all)
+	}
+}
+
+func TestChallenge55(t *testing.T) {
+	a, b := FindCollision(detrand.New(55))
+	testutil.AssertEqual(t, false, bytes.Equal(a, b))
+	testutil.AssertEqual(t, Sum(a), Sum(b))
+}
@@ -0,0 +1,113 @@
+package mdhash
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// FindBlockCollision uses the birthday paradox to find two different blocks
+// which take state to the same next state. It takes around 2^(bits/2) calls
+// to the compression function. The blocks are read from rand, or
+// crypto/rand if it's nil.
+func FindBlockCollision(rand io.Reader, h *Hash, state []byte) (a, b, next []byte) {
+	return findBlockCollision(random.New(rand), h, state)
+}
+
+func findBlockCollision(src *random.Source, h *Hash, state []byte) (a, b, next []byte) {
+	seen := make(map[string][]byte)
+	for {
+		block := src.Key()
+		out := h.Compress(state, block)
+		if prev, ok := seen[string(out)]; ok && !bytes.Equal(prev, block) {
+			return prev, block, out
+		}
+		seen[string(out)] = block
+	}
+}
+
+// Multicollision is a Joux multicollision. Picking either block from each
+// pair gives one of 2^len(pairs) messages, which all take the initial state
+// to the same final state.
+type Multicollision struct {
+	pairs [][2][]byte
+	state []byte // the chaining state every message ends up in
+	src   *random.Source
+}
+
+// NewMulticollision finds 2^n messages which collide from state, using n
+// block collisions and so only n*2^(bits/2) work. The blocks, including
+// the ones Extend finds, are read from rand, or crypto/rand if it's nil.
+func NewMulticollision(rand io.Reader, h *Hash, state []byte, n int) *Multicollision {
+	m := &Multicollision{state: state, src: random.New(rand)}
+	for i := 0; i < n; i++ {
+		m.Extend(h)
+	}
+	return m
+}
+
+// Extend doubles the number of colliding messages by finding another block
+// collision from the current final state.
+func (m *Multicollision) Extend(h *Hash) {
+	a, b, next := findBlockCollision(m.src, h, m.state)
+	m.pairs = append(m.pairs, [2][]byte{a, b})
+	m.state = next
+}
+
+// Message returns the i'th colliding message, where bit j of i chooses the
+// block from the j'th pair.
+func (m *Multicollision) Message(i uint64) []byte {
+	var res []byte
+	for j, pair := range m.pairs {
+		res = append(res, pair[(i>>uint(j))&1]...)
+	}
+	return res
+}
+
+// FindCascadeCollision finds two different messages which collide under the
+// cascade f(m) || g(m), where f is cheap to attack. We generate 2^(b/2)
+// messages which already collide under f, where b is the size of g, and
+// expect a pair of them to collide under g too. If they don't, doubling the
+// number of messages gives us another go. The blocks are read from rand, or
+// crypto/rand if it's nil.
+func FindCascadeCollision(rand io.Reader, f, g *Hash) (a, b []byte) {
+	m := NewMulticollision(rand, f, f.iv, g.bits/2)
+	for {
+		if i, j, ok := findSumCollision(g, m); ok {
+			return m.Message(i), m.Message(j)
+		}
+		m.Extend(f)
+	}
+}
+
+// findSumCollision looks for two messages in m with the same hash under h.
+// The messages share most of their blocks, so we walk the tree of prefixes
+// rather than hashing each message from scratch.
+func findSumCollision(h *Hash, m *Multicollision) (i, j uint64, ok bool) {
+	n := len(m.pairs)
+	tail := h.Pad(make([]byte, n*BlockSize))[n*BlockSize:]
+	seen := make(map[string]uint64)
+
+	var walk func(state []byte, depth int, index uint64) bool
+	walk = func(state []byte, depth int, index uint64) bool {
+		if depth == n {
+			sum := string(h.Chain(state, tail))
+			if prev, found := seen[sum]; found {
+				i, j = prev, index
+				return true
+			}
+			seen[sum] = index
+			return false
+		}
+		for k, block := range m.pairs[depth] {
+			if walk(h.Compress(state, block), depth+1, index|uint64(k)<<uint(depth)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ok = walk(h.iv, 0, 0)
+	return
+}
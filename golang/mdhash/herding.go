@@ -0,0 +1,134 @@
+package mdhash
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Diamond is a Kelsey-Kohno diamond structure: a binary tree of collisions
+// which funnels 2^k different chaining states into a single one.
+type Diamond struct {
+	h *Hash
+
+	// states[0] are the 2^k leaves, and states[k] is just the root.
+	states [][][]byte
+
+	// blocks[i][j] takes states[i][j] to states[i+1][j/2].
+	blocks [][][]byte
+
+	src *random.Source
+}
+
+// BuildDiamond builds a diamond structure with 2^k leaves. Finding the
+// 2^k-1 collisions is the expensive part, and the collisions on each level
+// are independent of each other, so we spread them across workers
+// goroutines. If workers is less than 1, we use one per CPU.
+//
+// Everything random, including the links Herd finds, comes from rand, or
+// crypto/rand if it's nil. Each collision gets its own Source split from
+// it, so the diamond doesn't depend on how many workers there are.
+func BuildDiamond(rand io.Reader, h *Hash, k, workers int) *Diamond {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	d := &Diamond{h: h, src: random.New(rand)}
+
+	// Start from distinct random states.
+	leaves := make([][]byte, 0, 1<<uint(k))
+	seen := make(map[string]bool)
+	for len(leaves) < cap(leaves) {
+		leaf := h.truncate(d.src.Key())
+		if !seen[string(leaf)] {
+			seen[string(leaf)] = true
+			leaves = append(leaves, leaf)
+		}
+	}
+	d.states = append(d.states, leaves)
+
+	for level := 0; level < k; level++ {
+		states := d.states[level]
+		blocks := make([][]byte, len(states))
+		next := make([][]byte, len(states)/2)
+		srcs := make([]*random.Source, len(next))
+		for j := range srcs {
+			srcs[j] = d.src.Split()
+		}
+
+		pairs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// Each pair writes to its own slots, so no locking is needed.
+				for j := range pairs {
+					blocks[2*j], blocks[2*j+1], next[j] = findStateCollision(srcs[j], h, states[2*j], states[2*j+1])
+				}
+			}()
+		}
+		for j := range next {
+			pairs <- j
+		}
+		close(pairs)
+		wg.Wait()
+
+		d.blocks = append(d.blocks, blocks)
+		d.states = append(d.states, next)
+	}
+
+	return d
+}
+
+// Root returns the state at the bottom of the diamond.
+func (d *Diamond) Root() []byte {
+	return d.states[len(d.states)-1][0]
+}
+
+// path returns the blocks which take the given leaf to the root.
+func (d *Diamond) path(leaf int) []byte {
+	var res []byte
+	for _, blocks := range d.blocks {
+		res = append(res, blocks[leaf]...)
+		leaf /= 2
+	}
+	return res
+}
+
+// Prediction returns the hash we commit to in advance. It's the hash of any
+// message made of prefixBlocks blocks, a block linking them to a leaf, and
+// then the path from that leaf through the diamond.
+func (d *Diamond) Prediction(prefixBlocks int) []byte {
+	n := (prefixBlocks + 1 + len(d.blocks)) * BlockSize
+	tail := d.h.Pad(make([]byte, n))[n:]
+	return d.h.Chain(d.Root(), tail)
+}
+
+// Herd returns a message starting with prefix, padded with spaces to
+// prefixBlocks blocks, which hashes to Prediction(prefixBlocks). The only
+// work left to do is to find a block linking the prefix to one of the leaves.
+func (d *Diamond) Herd(prefix []byte, prefixBlocks int) []byte {
+	if len(prefix) > prefixBlocks*BlockSize {
+		panic("herd: prefix too long")
+	}
+	msg := append([]byte{}, prefix...)
+	msg = append(msg, bytes.Repeat([]byte{' '}, prefixBlocks*BlockSize-len(prefix))...)
+	state := d.h.Chain(d.h.iv, msg)
+
+	leaves := make(map[string]int)
+	for i, leaf := range d.states[0] {
+		leaves[string(leaf)] = i
+	}
+
+	for {
+		link := d.src.Key()
+		if i, ok := leaves[string(d.h.Compress(state, link))]; ok {
+			msg = append(msg, link...)
+			return append(msg, d.path(i)...)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package mdhash is a toy Merkle-Damgård hash function with a tiny state,
+// and the generic attacks on that construction: multicollisions, second
+// preimages and herding.
+package mdhash
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"fmt"
+)
+
+// BlockSize is the size of a message block for Hash. Each block is used
+// as an AES key.
+const BlockSize = aes.BlockSize
+
+// Hash is a deliberately weak Merkle-Damgård hash function. The
+// compression function encrypts the chaining state under AES, keyed by the
+// message block, and truncates the result to a handful of bits. That keeps
+// the state small enough for birthday attacks to run in a unit test.
+type Hash struct {
+	bits int    // Size of the chaining state in bits
+	iv   []byte // initial chaining state
+}
+
+// New returns a toy hash with a chaining state of the given number of
+// bits, which must be between 16 and 32.
+func New(bits int) *Hash {
+	if bits < 16 || bits > 32 {
+		panic(fmt.Sprintf("New: unsupported state size %d", bits))
+	}
+	h := &Hash{bits: bits}
+	h.iv = h.truncate([]byte{0x01, 0x23, 0x45, 0x67})
+	return h
+}
+
+// IV returns the initial chaining state.
+func (h *Hash) IV() []byte {
+	return h.iv
+}
+
+// Size returns the size of the chaining state (and the hash) in bytes.
+func (h *Hash) Size() int {
+	return (h.bits + 7) / 8
+}
+
+// truncate returns the first bits of buf, with any unused bits of the last
+// byte cleared.
+func (h *Hash) truncate(buf []byte) []byte {
+	res := make([]byte, h.Size())
+	copy(res, buf)
+	res[len(res)-1] &= 0xff << uint(8*len(res)-h.bits)
+	return res
+}
+
+// Compress is the compression function, taking a chaining state and a
+// single message block to the next chaining state.
+func (h *Hash) Compress(state, block []byte) []byte {
+	b, err := aes.NewCipher(block)
+	if err != nil {
+		panic(err)
+	}
+	buf := make([]byte, aes.BlockSize)
+	copy(buf, state)
+	b.Encrypt(buf, buf)
+	return h.truncate(buf)
+}
+
+// Chain runs the compression function over msg, which must be a multiple of
+// the block size, starting from state.
+func (h *Hash) Chain(state, msg []byte) []byte {
+	if len(msg)%BlockSize != 0 {
+		panic("chain: need a multiple of the blocksize")
+	}
+	for i := 0; i < len(msg); i += BlockSize {
+		state = h.Compress(state, msg[i:i+BlockSize])
+	}
+	return state
+}
+
+// Pad applies Merkle-Damgård strengthening to msg: a 1 bit, enough 0 bits
+// to leave room for the length, and then the length of msg in bits.
+func (h *Hash) Pad(msg []byte) []byte {
+	n := len(msg) + 1 + 8
+	n += (BlockSize - n%BlockSize) % BlockSize
+
+	res := make([]byte, n)
+	copy(res, msg)
+	res[len(msg)] = 0x80
+	binary.BigEndian.PutUint64(res[n-8:], uint64(len(msg))*8)
+	return res
+}
+
+// Sum returns the hash of msg.
+func (h *Hash) Sum(msg []byte) []byte {
+	return h.Chain(h.iv, h.Pad(msg))
+}
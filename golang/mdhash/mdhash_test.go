@@ -0,0 +1,89 @@
+package mdhash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestMDHashPadding(t *testing.T) {
+	h := New(16)
+	for i := 0; i < 3*BlockSize; i++ {
+		padded := h.Pad(bytes.Repeat([]byte{'A'}, i))
+		testutil.AssertEqual(t, 0, len(padded)%BlockSize)
+		testutil.AssertEqual(t, true, len(padded) >= i+9)
+		testutil.AssertEqual(t, true, len(padded) < i+9+BlockSize)
+	}
+}
+
+func TestChallenge52(t *testing.T) {
+	h := New(16)
+	m := NewMulticollision(detrand.New(52), h, h.iv, 4)
+	seen := make(map[string]bool)
+	for i := uint64(0); i < 16; i++ {
+		msg := m.Message(i)
+		seen[string(msg)] = true
+		testutil.AssertEqual(t, h.Sum(m.Message(0)), h.Sum(msg))
+	}
+	testutil.AssertEqual(t, 16, len(seen))
+
+	f, g := New(16), New(32)
+	a, b := FindCascadeCollision(detrand.New(52), f, g)
+	testutil.AssertEqual(t, false, bytes.Equal(a, b))
+	testutil.AssertEqual(t, f.Sum(a), f.Sum(b))
+	testutil.AssertEqual(t, g.Sum(a), g.Sum(b))
+}
+
+func TestExpandableMessage(t *testing.T) {
+	h := New(16)
+	e := NewExpandableMessage(detrand.New(53), h, h.iv, 4)
+	testutil.AssertEqual(t, 4, e.MinBlocks())
+	testutil.AssertEqual(t, 19, e.MaxBlocks())
+
+	for n := e.MinBlocks(); n <= e.MaxBlocks(); n++ {
+		msg := e.Message(n)
+		testutil.AssertEqual(t, n*BlockSize, len(msg))
+		testutil.AssertEqual(t, e.state, h.Chain(h.iv, msg))
+	}
+}
+
+func TestChallenge53(t *testing.T) {
+	h := New(24)
+	k := 10
+	rand := detrand.New(53)
+	msg := make([]byte, (1<<uint(k))*BlockSize)
+	rand.Read(msg)
+
+	forged := FindSecondPreimage(rand, h, msg, k)
+	testutil.AssertEqual(t, false, bytes.Equal(msg, forged))
+	testutil.AssertEqual(t, h.Sum(msg), h.Sum(forged))
+}
+
+func TestBuildDiamond(t *testing.T) {
+	h := New(16)
+	var roots [][]byte
+	for _, workers := range []int{1, 3} {
+		d := BuildDiamond(detrand.New(54), h, 4, workers)
+		testutil.AssertEqual(t, 16, len(d.states[0]))
+		for i, leaf := range d.states[0] {
+			testutil.AssertEqual(t, d.Root(), h.Chain(leaf, d.path(i)))
+		}
+		roots = append(roots, d.Root())
+	}
+	// the same randomness gives the same diamond, however many workers
+	// build it
+	testutil.AssertEqual(t, roots[0], roots[1])
+}
+
+func TestChallenge54(t *testing.T) {
+	h := New(20)
+	d := BuildDiamond(detrand.New(54), h, 6, 4)
+	prediction := d.Prediction(4)
+
+	prefix := []byte("Final scores: Arsenal 3, Spurs 0.")
+	forged := d.Herd(prefix, 4)
+	testutil.AssertEqual(t, true, bytes.HasPrefix(forged, prefix))
+	testutil.AssertEqual(t, prediction, h.Sum(forged))
+}
@@ -0,0 +1,119 @@
+package mdhash
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// ExpandableMessage is a set of messages of every length between k and
+// k+2^k-1 blocks, which all take the initial state to the same final state.
+type ExpandableMessage struct {
+	// pieces[i] is a single block, and a message of 2^(k-1-i)+1 blocks,
+	// which collide from the state left by the pieces before it.
+	pieces [][2][]byte
+	state  []byte // the chaining state every message ends up in
+}
+
+// NewExpandableMessage builds an expandable message from state, following
+// Kelsey and Schneier. It takes k collisions, each costing 2^(bits/2) work
+// plus the work to hash the longer message. The blocks are read from rand,
+// or crypto/rand if it's nil.
+func NewExpandableMessage(rand io.Reader, h *Hash, state []byte, k int) *ExpandableMessage {
+	return newExpandableMessage(random.New(rand), h, state, k)
+}
+
+func newExpandableMessage(src *random.Source, h *Hash, state []byte, k int) *ExpandableMessage {
+	e := &ExpandableMessage{state: state}
+	for i := k - 1; i >= 0; i-- {
+		short, long, next := findExpandingCollision(src, h, e.state, 1<<uint(i))
+		e.pieces = append(e.pieces, [2][]byte{short, long})
+		e.state = next
+	}
+	return e
+}
+
+// findExpandingCollision finds a single block message and a message of n+1
+// blocks which collide from state. The long message is n dummy blocks
+// followed by a block found by the birthday attack.
+func findExpandingCollision(src *random.Source, h *Hash, state []byte, n int) (short, long, next []byte) {
+	dummy := make([]byte, n*BlockSize)
+	short, last, next := findStateCollision(src, h, state, h.Chain(state, dummy))
+	return short, append(dummy, last...), next
+}
+
+// findStateCollision uses the birthday paradox to find blocks a and b which
+// take the two different states s and t to the same next state.
+func findStateCollision(src *random.Source, h *Hash, s, t []byte) (a, b, next []byte) {
+	fromS := make(map[string][]byte)
+	fromT := make(map[string][]byte)
+	for {
+		a = src.Key()
+		out := string(h.Compress(s, a))
+		if b, ok := fromT[out]; ok {
+			return a, b, []byte(out)
+		}
+		fromS[out] = a
+
+		b = src.Key()
+		out = string(h.Compress(t, b))
+		if a, ok := fromS[out]; ok {
+			return a, b, []byte(out)
+		}
+		fromT[out] = b
+	}
+}
+
+// MinBlocks and MaxBlocks are the shortest and longest messages, in blocks.
+func (e *ExpandableMessage) MinBlocks() int { return len(e.pieces) }
+func (e *ExpandableMessage) MaxBlocks() int { return len(e.pieces) + 1<<uint(len(e.pieces)) - 1 }
+
+// Message returns the expandable message which is n blocks long.
+func (e *ExpandableMessage) Message(n int) []byte {
+	if n < e.MinBlocks() || n > e.MaxBlocks() {
+		panic(fmt.Sprintf("message: can't make a message of %d blocks", n))
+	}
+	extra := n - e.MinBlocks()
+	k := len(e.pieces)
+
+	var res []byte
+	for i, piece := range e.pieces {
+		// piece i can add 2^(k-1-i) blocks
+		res = append(res, piece[(extra>>uint(k-1-i))&1]...)
+	}
+	return res
+}
+
+// FindSecondPreimage returns a different message with the same hash as msg,
+// which needs to be at least k+1 blocks long, and ideally around 2^k blocks.
+// We link an expandable message into one of the intermediate states of msg,
+// and then pick the length which makes the padding come out the same. The
+// blocks are read from rand, or crypto/rand if it's nil.
+func FindSecondPreimage(rand io.Reader, h *Hash, msg []byte, k int) []byte {
+	src := random.New(rand)
+	e := newExpandableMessage(src, h, h.iv, k)
+
+	// states maps the chaining state after each full block of msg to the
+	// number of blocks hashed to get there. Only the states we could reach
+	// with an expandable message and a bridge block are any use.
+	states := make(map[string]int)
+	state := h.iv
+	for n := 1; n*BlockSize <= len(msg); n++ {
+		state = h.Compress(state, msg[(n-1)*BlockSize:n*BlockSize])
+		if n-1 >= e.MinBlocks() && n-1 <= e.MaxBlocks() {
+			states[string(state)] = n
+		}
+	}
+	if len(states) == 0 {
+		panic("FindSecondPreimage: message too short")
+	}
+
+	for {
+		bridge := src.Key()
+		if n, ok := states[string(h.Compress(e.state, bridge))]; ok {
+			res := append(e.Message(n-1), bridge...)
+			return append(res, msg[n*BlockSize:]...)
+		}
+	}
+}
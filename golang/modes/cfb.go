@@ -0,0 +1,108 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// ErrNotFullSegments is returned when CFB is given something which isn't a
+// whole number of segments.
+var ErrNotFullSegments = errors.New("modes: input not full segments")
+
+// CFB is a block cipher in cipher feedback mode. Each segment of the
+// message is XORed with the leftmost bits of the encrypted shift register,
+// and the resulting ciphertext is shifted in on the right. NIST calls AES
+// with 1, 8 and 128 bit segments CFB1, CFB8 and CFB128.
+type CFB struct {
+	block       cipher.Block
+	iv          []byte
+	segmentBits int
+}
+
+// NewCFB returns a BlockCipher which uses b in CFB mode with the given IV
+// and segment size in bits, which can be anything from 1 up to the block
+// size. Messages have to be a whole number of segments, so any length will
+// do for segments of 8 bits or fewer.
+func NewCFB(b cipher.Block, iv []byte, segmentBits int) BlockCipher {
+	if len(iv) != b.BlockSize() {
+		panic("NewCFB: IV length must equal block size")
+	}
+	if segmentBits < 1 || segmentBits > 8*b.BlockSize() {
+		panic("NewCFB: segment size must be between 1 bit and the block size")
+	}
+	return &CFB{
+		block:       b,
+		iv:          append([]byte(nil), iv...),
+		segmentBits: segmentBits,
+	}
+}
+
+// Decrypt decrypts cipherText.
+func (c *CFB) Decrypt(cipherText []byte) ([]byte, error) {
+	return c.crypt(cipherText, true)
+}
+
+// Encrypt encrypts plainText.
+func (c *CFB) Encrypt(plainText []byte) ([]byte, error) {
+	return c.crypt(plainText, false)
+}
+
+func (c *CFB) crypt(in []byte, decrypt bool) ([]byte, error) {
+	s := c.segmentBits
+	if len(in)*8%s != 0 {
+		return nil, ErrNotFullSegments
+	}
+
+	out := make([]byte, len(in))
+	register := append([]byte(nil), c.iv...)
+	keystream := make([]byte, len(register))
+	for i := 0; i < len(in)*8; i += s {
+		c.block.Encrypt(keystream, register)
+
+		// The ciphertext segment is what goes back into the register.
+		cipherText := out
+		if decrypt {
+			cipherText = in
+		}
+		if s%8 == 0 && i%8 == 0 {
+			n, j := s/8, i/8
+			xorcipher.XORBytes(out[j:j+n], in[j:j+n], keystream)
+			copy(register, register[n:])
+			copy(register[len(register)-n:], cipherText[j:j+n])
+			continue
+		}
+		for k := 0; k < s; k++ {
+			setBit(out, i+k, bit(in, i+k)^bit(keystream, k))
+		}
+		shiftLeft(register, s)
+		for k := 0; k < s; k++ {
+			setBit(register, 8*len(register)-s+k, bit(cipherText, i+k))
+		}
+	}
+	return out, nil
+}
+
+// bit returns bit i of b, counting from the most significant bit of b[0],
+// which is how NIST numbers them.
+func bit(b []byte, i int) byte {
+	return b[i/8] >> (7 - uint(i%8)) & 1
+}
+
+// setBit sets bit i of b, numbered as for bit, to v.
+func setBit(b []byte, i int, v byte) {
+	mask := byte(1) << (7 - uint(i%8))
+	b[i/8] = b[i/8]&^mask | v<<(7-uint(i%8))
+}
+
+// shiftLeft shifts b left by n bits, filling in with zeros on the right.
+func shiftLeft(b []byte, n int) {
+	for i := 0; i < 8*len(b); i++ {
+		var v byte
+		if i+n < 8*len(b) {
+			v = bit(b, i+n)
+		}
+		setBit(b, i, v)
+	}
+}
@@ -0,0 +1,104 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+// ErrNotFullBlocks is returned when a block mode is given something which
+// isn't a whole number of blocks, once any padding has been added.
+var ErrNotFullBlocks = errors.New("modes: input not full blocks")
+
+// Cipher encrypts and decrypts whole messages of any length, adding and
+// checking the padding itself, so that callers never have to. Open returns
+// an *padding.Error if the padding is bad, rather than panicking or
+// handing back something half unpadded.
+type Cipher interface {
+	Seal(plainText []byte) ([]byte, error)
+	Open(cipherText []byte) ([]byte, error)
+}
+
+// blockModeCipher is a Cipher for the modes which work on whole blocks. It
+// makes a fresh cipher.BlockMode for each message, so that they all start
+// from the same IV.
+type blockModeCipher struct {
+	blockSize int
+	encrypter func() cipher.BlockMode
+	decrypter func() cipher.BlockMode
+	padding   padding.Scheme
+}
+
+// NewECBCipher returns a Cipher which uses b in ECB mode, with the given
+// padding.
+func NewECBCipher(b cipher.Block, p padding.Scheme) Cipher {
+	return &blockModeCipher{
+		blockSize: b.BlockSize(),
+		encrypter: func() cipher.BlockMode { return NewECBEncrypter(b) },
+		decrypter: func() cipher.BlockMode { return NewECBDecrypter(b) },
+		padding:   p,
+	}
+}
+
+// NewCBCCipher returns a Cipher which uses b in CBC mode, with the given
+// padding. Every message starts from iv.
+func NewCBCCipher(b cipher.Block, iv []byte, p padding.Scheme) Cipher {
+	iv = append([]byte(nil), iv...)
+	NewCBCEncrypter(b, iv) // check the IV now, rather than on first use
+	return &blockModeCipher{
+		blockSize: b.BlockSize(),
+		encrypter: func() cipher.BlockMode { return NewCBCEncrypter(b, iv) },
+		decrypter: func() cipher.BlockMode { return NewCBCDecrypter(b, iv) },
+		padding:   p,
+	}
+}
+
+func (c *blockModeCipher) Seal(plainText []byte) ([]byte, error) {
+	in := c.padding.Pad(plainText, c.blockSize)
+	if len(in)%c.blockSize != 0 {
+		return nil, ErrNotFullBlocks
+	}
+	out := make([]byte, len(in))
+	c.encrypter().CryptBlocks(out, in)
+	return out, nil
+}
+
+func (c *blockModeCipher) Open(cipherText []byte) ([]byte, error) {
+	if len(cipherText)%c.blockSize != 0 {
+		return nil, ErrNotFullBlocks
+	}
+	out := make([]byte, len(cipherText))
+	c.decrypter().CryptBlocks(out, cipherText)
+	return c.padding.Unpad(out, c.blockSize)
+}
+
+// streamCipher is a Cipher for the modes which turn a block cipher into a
+// stream cipher, which don't need any padding.
+type streamCipher struct {
+	stream func() cipher.Stream
+}
+
+// NewCTRCipher returns a Cipher which uses b in CTR mode with the given
+// nonce, as CTR does. It returns an error if the nonce is too long, as
+// NewCTRStream does. Every message starts from a count of 0.
+func NewCTRCipher(b cipher.Block, nonce []byte) (Cipher, error) {
+	s, err := NewCTRStream(b, nonce)
+	if err != nil {
+		return nil, err
+	}
+	iv := append([]byte(nil), s.counter...)
+	return &streamCipher{
+		stream: func() cipher.Stream { return newCTRStream(b, iv, challengeLayout) },
+	}, nil
+}
+
+func (c *streamCipher) Seal(plainText []byte) ([]byte, error) {
+	out := make([]byte, len(plainText))
+	c.stream().XORKeyStream(out, plainText)
+	return out, nil
+}
+
+func (c *streamCipher) Open(cipherText []byte) ([]byte, error) {
+	return c.Seal(cipherText)
+}
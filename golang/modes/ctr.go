@@ -0,0 +1,229 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// ErrCounterWrapped is returned when CTR mode would need more blocks of
+// keystream than the counter has values left, and so would start reusing
+// them.
+var ErrCounterWrapped = errors.New("modes: CTR counter wrapped around")
+
+// CounterLayout says how the counter is laid out in the counter block. The
+// counter takes up the last Bits bits of the block, and whatever comes
+// before it, usually a nonce, stays the same from one block to the next.
+// NIST SP 800-38A counts the whole 128 bit block, GCM counts the last 32
+// bits after a 96 bit nonce, and the challenges count the last 64 bits,
+// little endian.
+type CounterLayout struct {
+	Bits         int
+	LittleEndian bool
+}
+
+// check says whether the layout makes sense for a counter block of n
+// bytes.
+func (l CounterLayout) check(n int) error {
+	if l.Bits < 8 || l.Bits%8 != 0 || l.Bits > 8*n {
+		return fmt.Errorf("modes: counter must be a whole number of bytes, no bigger than the %d byte block, not %d bits", n, l.Bits)
+	}
+	return nil
+}
+
+// add adds n to the counter in block, and reports whether it carried out
+// of the top of the counter.
+func (l CounterLayout) add(block []byte, n uint64) bool {
+	size := l.Bits / 8
+	counter := block[len(block)-size:]
+	for i := 0; i < size && n != 0; i++ {
+		j := size - 1 - i
+		if l.LittleEndian {
+			j = i
+		}
+		sum := uint64(counter[j]) + n&0xff
+		counter[j] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	return n != 0
+}
+
+// CounterBlock returns the first counter block for the given nonce and
+// initial counter value, with the counter laid out after the nonce as
+// layout says.
+func CounterBlock(nonce []byte, layout CounterLayout, initial uint64) ([]byte, error) {
+	size := layout.Bits / 8
+	block := make([]byte, len(nonce)+size)
+	copy(block, nonce)
+	if err := layout.check(len(block)); err != nil {
+		return nil, err
+	}
+	if layout.add(block, initial) {
+		return nil, fmt.Errorf("modes: initial counter %d doesn't fit in %d bits", initial, layout.Bits)
+	}
+	return block, nil
+}
+
+// CTRStream is a block cipher in counter mode, as a cipher.Stream. The
+// keystream carries on from one call to XORKeyStream to the next, even
+// part way through a block.
+type CTRStream struct {
+	block     cipher.Block
+	layout    CounterLayout
+	counter   []byte
+	wrapped   bool
+	keystream []byte
+	used      int
+	scratch   []byte
+}
+
+// challengeLayout is the counter layout from challenge 18: a 64 bit little
+// endian block count after the nonce.
+var challengeLayout = CounterLayout{Bits: 64, LittleEndian: true}
+
+// NewCTRStream returns a CTRStream which uses b with the given nonce, as in
+// challenge 18. The counter block is the nonce, padded with zeros to leave
+// room for a 64 bit little endian block count, which starts from 0. It
+// returns an error if the nonce is too long to leave that room.
+func NewCTRStream(b cipher.Block, nonce []byte) (*CTRStream, error) {
+	room := b.BlockSize() - challengeLayout.Bits/8
+	if len(nonce) > room {
+		return nil, fmt.Errorf("modes: nonce can be at most %d bytes with a %d bit counter, not %d", room, challengeLayout.Bits, len(nonce))
+	}
+	iv, err := CounterBlock(append(append([]byte(nil), nonce...), make([]byte, room-len(nonce))...), challengeLayout, 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewCounterStream(b, iv, challengeLayout)
+}
+
+// NewCounterStream returns a CTRStream which uses b, starting from counter
+// block iv, and counting as layout says. CounterBlock makes iv from a nonce
+// and an initial counter value.
+func NewCounterStream(b cipher.Block, iv []byte, layout CounterLayout) (*CTRStream, error) {
+	if len(iv) != b.BlockSize() {
+		return nil, fmt.Errorf("modes: counter block must be %d bytes, not %d", b.BlockSize(), len(iv))
+	}
+	if err := layout.check(len(iv)); err != nil {
+		return nil, err
+	}
+	return newCTRStream(b, iv, layout), nil
+}
+
+func newCTRStream(b cipher.Block, iv []byte, layout CounterLayout) *CTRStream {
+	bs := b.BlockSize()
+	return &CTRStream{
+		block:     b,
+		layout:    layout,
+		counter:   append([]byte(nil), iv...),
+		keystream: make([]byte, bs),
+		used:      bs,
+		scratch:   make([]byte, bs),
+	}
+}
+
+// XORKeyStream XORs each byte of src with the next byte of the keystream,
+// and writes the result to dst. Encryption and decryption are the same
+// operation. Since cipher.Stream has no way to return an error, it panics
+// with ErrCounterWrapped if the counter runs out; use Crypt to get the
+// error instead.
+func (s *CTRStream) XORKeyStream(dst, src []byte) {
+	if err := s.Crypt(dst, src); err != nil {
+		panic(err)
+	}
+}
+
+// Crypt is XORKeyStream, except that it returns ErrCounterWrapped if there
+// isn't enough counter left for all of src, without touching dst or moving
+// the keystream on.
+func (s *CTRStream) Crypt(dst, src []byte) error {
+	if len(dst) < len(src) {
+		panic("modes: output smaller than input")
+	}
+	if err := s.check(len(src)); err != nil {
+		return err
+	}
+
+	for len(src) > 0 {
+		if s.used == len(s.keystream) {
+			s.block.Encrypt(s.keystream, s.counter)
+			s.used = 0
+			s.wrapped = s.layout.add(s.counter, 1)
+		}
+		n := xorcipher.XORBytes(dst, src, s.keystream[s.used:])
+		s.used += n
+		dst, src = dst[n:], src[n:]
+	}
+	return nil
+}
+
+// check returns ErrCounterWrapped if n more bytes of keystream would need
+// the counter to go past its largest value.
+func (s *CTRStream) check(n int) error {
+	n -= len(s.keystream) - s.used
+	if n <= 0 {
+		return nil
+	}
+	if s.wrapped {
+		return ErrCounterWrapped
+	}
+	blocks := (n + len(s.keystream) - 1) / len(s.keystream)
+	last := s.scratch
+	copy(last, s.counter)
+	if s.layout.add(last, uint64(blocks-1)) {
+		return ErrCounterWrapped
+	}
+	return nil
+}
+
+// CounterMode is a block cipher in counter mode with any counter layout,
+// as a BlockCipher. Every message starts from the same counter block.
+type CounterMode struct {
+	block  cipher.Block
+	iv     []byte
+	layout CounterLayout
+}
+
+// NewCounterMode returns a BlockCipher which uses b in CTR mode, starting
+// each message from counter block iv and counting as layout says.
+func NewCounterMode(b cipher.Block, iv []byte, layout CounterLayout) (BlockCipher, error) {
+	if _, err := NewCounterStream(b, iv, layout); err != nil {
+		return nil, err
+	}
+	return &CounterMode{
+		block:  b,
+		iv:     append([]byte(nil), iv...),
+		layout: layout,
+	}, nil
+}
+
+// Decrypt decrypts cipherText.
+func (c *CounterMode) Decrypt(cipherText []byte) ([]byte, error) {
+	return c.Encrypt(cipherText)
+}
+
+// Encrypt encrypts plainText. It returns ErrCounterWrapped if plainText is
+// too long for the counter.
+func (c *CounterMode) Encrypt(plainText []byte) ([]byte, error) {
+	out := make([]byte, len(plainText))
+	if err := newCTRStream(c.block, c.iv, c.layout).Crypt(out, plainText); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CTR runs b in counter mode over in, as a CTRStream with the given nonce
+// would. Encryption and decryption are the same operation.
+func CTR(b cipher.Block, in, nonce []byte) ([]byte, error) {
+	s, err := NewCTRStream(b, nonce)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	if err := s.Crypt(out, in); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,125 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// ErrShortInput is returned when a mode which steals ciphertext is given
+// less than one whole block.
+var ErrShortInput = errors.New("modes: input shorter than a block")
+
+// CTSVariant picks how CTS lays out the last two blocks of ciphertext. The
+// names come from the addendum to NIST SP 800-38A.
+type CTSVariant int
+
+// The ciphertext stealing variants. In every one of them the second to
+// last ciphertext block is cut down to the length of the last plaintext
+// block, and they only differ in where it goes.
+const (
+	// CS1 keeps the blocks in order.
+	CS1 CTSVariant = iota + 1
+	// CS2 swaps the last two blocks, unless the message was a whole
+	// number of blocks, when it's the same as CBC.
+	CS2
+	// CS3 always swaps the last two blocks, as Kerberos does.
+	CS3
+)
+
+// CTS is a block cipher in CBC mode with ciphertext stealing. There's no
+// padding: the ciphertext is exactly as long as the plaintext, which has
+// to be at least one block.
+type CTS struct {
+	block   cipher.Block
+	iv      []byte
+	variant CTSVariant
+}
+
+// NewCTS returns a BlockCipher which uses b in CBC mode with ciphertext
+// stealing, laid out as the given variant.
+func NewCTS(b cipher.Block, iv []byte, variant CTSVariant) BlockCipher {
+	if len(iv) != b.BlockSize() {
+		panic("NewCTS: IV length must equal block size")
+	}
+	if variant < CS1 || variant > CS3 {
+		panic("NewCTS: unknown variant")
+	}
+	return &CTS{
+		block:   b,
+		iv:      append([]byte(nil), iv...),
+		variant: variant,
+	}
+}
+
+// swapped says whether the last two blocks of a message, the last of which
+// is d bytes long, trade places.
+func (c *CTS) swapped(d int) bool {
+	return c.variant == CS3 || c.variant == CS2 && d != c.block.BlockSize()
+}
+
+// Decrypt decrypts cipherText.
+func (c *CTS) Decrypt(cipherText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(cipherText) < bs {
+		return nil, ErrShortInput
+	}
+
+	plainText := make([]byte, len(cipherText))
+	n := (len(cipherText) + bs - 1) / bs
+	if n == 1 {
+		cbcDecrypt(c.block, append([]byte(nil), c.iv...), make([]byte, bs), plainText, cipherText)
+		return plainText, nil
+	}
+
+	// Put the last two blocks back in CS1 order, and decrypt the last one.
+	// Where the plaintext was padded with zeros, that leaves the tail of
+	// the block which was stolen from.
+	d := len(cipherText) - (n-1)*bs
+	tail := cipherText[(n-2)*bs:]
+	stolen, last := tail[:d], tail[d:]
+	if c.swapped(d) {
+		last, stolen = tail[:bs], tail[bs:]
+	}
+	z := make([]byte, bs)
+	c.block.Decrypt(z, last)
+
+	full := make([]byte, (n-1)*bs)
+	copy(full, cipherText[:(n-2)*bs])
+	copy(full[(n-2)*bs:], stolen)
+	copy(full[(n-2)*bs+d:], z[d:])
+
+	cbcDecrypt(c.block, append([]byte(nil), c.iv...), make([]byte, bs), plainText, full)
+	for j := 0; j < d; j++ {
+		plainText[(n-1)*bs+j] = z[j] ^ stolen[j]
+	}
+	return plainText, nil
+}
+
+// Encrypt encrypts plainText.
+func (c *CTS) Encrypt(plainText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(plainText) < bs {
+		return nil, ErrShortInput
+	}
+
+	// Pad with zeros and encrypt as CBC, then drop the bytes of the second
+	// to last block which decryption can get back from the last one.
+	n := (len(plainText) + bs - 1) / bs
+	d := len(plainText) - (n-1)*bs
+	buf := make([]byte, n*bs)
+	copy(buf, plainText)
+	cbcEncrypt(c.block, append([]byte(nil), c.iv...), buf, buf)
+	if n == 1 {
+		return buf, nil
+	}
+
+	cipherText := make([]byte, 0, len(plainText))
+	cipherText = append(cipherText, buf[:(n-2)*bs]...)
+	stolen, last := buf[(n-2)*bs:(n-2)*bs+d], buf[(n-1)*bs:]
+	if c.swapped(d) {
+		cipherText = append(cipherText, last...)
+		return append(cipherText, stolen...), nil
+	}
+	cipherText = append(cipherText, stolen...)
+	return append(cipherText, last...), nil
+}
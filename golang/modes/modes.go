@@ -0,0 +1,293 @@
+// Package modes implements the block cipher modes of operation which the
+// challenges are built on, by hand rather than with crypto/cipher, because
+// you don't learn anything that way.
+package modes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// Mode identifies a mode of operation.
+type Mode int
+
+// The modes of operation we know how to tell apart.
+const (
+	ModeUnknown Mode = iota + 1
+	ModeECB
+	ModeCBC
+	ModeCTR
+)
+
+var modeName = [...]string{
+	ModeUnknown: "Unknown",
+	ModeECB:     "ECB",
+	ModeCBC:     "CBC",
+	ModeCTR:     "CTR",
+}
+
+func (m Mode) String() string {
+	return modeName[m]
+}
+
+// BlockCipher encrypts and decrypts whole messages. ECB, CBC and PCBC need
+// them to be a multiple of the block size, and the other modes say what
+// they'll take. Cipher is usually easier to use, since it looks after the
+// padding.
+type BlockCipher interface {
+	Decrypt(cipherText []byte) ([]byte, error)
+	Encrypt(plainText []byte) ([]byte, error)
+}
+
+// ECB is a block cipher in electronic codebook mode. As well as
+// BlockCipher, it implements cipher.BlockMode, so it can be used anywhere
+// the standard library's modes can.
+type ECB struct {
+	block   cipher.Block
+	decrypt bool
+}
+
+// NewECB returns a BlockCipher which uses b in ECB mode.
+func NewECB(b cipher.Block) BlockCipher {
+	return &ECB{
+		block: b,
+	}
+}
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts with b in ECB
+// mode.
+func NewECBEncrypter(b cipher.Block) cipher.BlockMode {
+	return &ECB{
+		block: b,
+	}
+}
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts with b in ECB
+// mode.
+func NewECBDecrypter(b cipher.Block) cipher.BlockMode {
+	return &ECB{
+		block:   b,
+		decrypt: true,
+	}
+}
+
+// BlockSize returns the block size of the underlying block cipher.
+func (c *ECB) BlockSize() int {
+	return c.block.BlockSize()
+}
+
+// CryptBlocks encrypts or decrypts src into dst, depending on how c was
+// made. It encrypts unless c came from NewECBDecrypter. Like the standard
+// library's modes, it panics if src isn't a whole number of blocks or dst
+// is too small, and dst and src may overlap exactly.
+func (c *ECB) CryptBlocks(dst, src []byte) {
+	checkBlocks(c.block.BlockSize(), dst, src)
+	if c.decrypt {
+		ecbDecrypt(c.block, dst, src)
+	} else {
+		ecbEncrypt(c.block, dst, src)
+	}
+}
+
+// Decrypt decrypts cipherText, leaving any padding in place.
+func (c *ECB) Decrypt(cipherText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(cipherText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	plainText := make([]byte, len(cipherText))
+	ecbDecrypt(c.block, plainText, cipherText)
+
+	return plainText, nil
+}
+
+// Encrypt encrypts plainText, which must already be padded.
+func (c *ECB) Encrypt(plainText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+
+	if len(plainText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	cipherText := make([]byte, len(plainText))
+	ecbEncrypt(c.block, cipherText, plainText)
+
+	return cipherText, nil
+}
+
+func ecbEncrypt(b cipher.Block, dst, src []byte) {
+	bs := b.BlockSize()
+	for i := 0; i < len(src); i += bs {
+		b.Encrypt(dst[i:], src[i:])
+	}
+}
+
+func ecbDecrypt(b cipher.Block, dst, src []byte) {
+	bs := b.BlockSize()
+	for i := 0; i < len(src); i += bs {
+		b.Decrypt(dst[i:], src[i:])
+	}
+}
+
+// CBC is a block cipher in cipher block chaining mode. Every message passed
+// to Encrypt or Decrypt starts from the same IV. It also implements
+// cipher.BlockMode, and then, like the standard library's CBC, the chain
+// carries on from one call to CryptBlocks to the next.
+type CBC struct {
+	block   cipher.Block
+	iv      []byte
+	chain   []byte
+	saved   []byte
+	decrypt bool
+}
+
+// NewCBC returns a BlockCipher which uses b in CBC mode with the given IV.
+func NewCBC(b cipher.Block, iv []byte) BlockCipher {
+	return newCBC(b, iv, false)
+}
+
+// NewCBCEncrypter returns a cipher.BlockMode which encrypts with b in CBC
+// mode, starting from iv.
+func NewCBCEncrypter(b cipher.Block, iv []byte) cipher.BlockMode {
+	return newCBC(b, iv, false)
+}
+
+// NewCBCDecrypter returns a cipher.BlockMode which decrypts with b in CBC
+// mode, starting from iv.
+func NewCBCDecrypter(b cipher.Block, iv []byte) cipher.BlockMode {
+	return newCBC(b, iv, true)
+}
+
+func newCBC(b cipher.Block, iv []byte, decrypt bool) *CBC {
+	if len(iv) != b.BlockSize() {
+		panic("NewCBC: IV length must equal block size")
+	}
+	return &CBC{
+		block:   b,
+		iv:      iv,
+		chain:   append([]byte(nil), iv...),
+		saved:   make([]byte, len(iv)),
+		decrypt: decrypt,
+	}
+}
+
+// BlockSize returns the block size of the underlying block cipher.
+func (c *CBC) BlockSize() int {
+	return c.block.BlockSize()
+}
+
+// CryptBlocks encrypts or decrypts src into dst, depending on how c was
+// made, carrying on from where the last call left off. It encrypts unless
+// c came from NewCBCDecrypter. Like the standard library's modes, it
+// panics if src isn't a whole number of blocks or dst is too small, and dst
+// and src may overlap exactly.
+func (c *CBC) CryptBlocks(dst, src []byte) {
+	checkBlocks(c.block.BlockSize(), dst, src)
+	if c.decrypt {
+		cbcDecrypt(c.block, c.chain, c.saved, dst, src)
+	} else {
+		cbcEncrypt(c.block, c.chain, dst, src)
+	}
+}
+
+// Decrypt decrypts cipherText, leaving any padding in place.
+func (c *CBC) Decrypt(cipherText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(cipherText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	plainText := make([]byte, len(cipherText))
+	cbcDecrypt(c.block, append([]byte(nil), c.iv...), make([]byte, bs), plainText, cipherText)
+
+	// If the original plainText lengths are not a multiple of the block
+	// size, padding would have to be added when encrypting, which would be
+	// removed at this point. For an example, see
+	// https://tools.ietf.org/html/rfc5246#section-6.2.3.2. However, it's
+	// critical to note that cipherTexts must be authenticated (i.e. by
+	// using crypto/hmac) before being decrypted in order to avoid creating
+	// a padding oracle.
+
+	return plainText, nil
+}
+
+// Encrypt encrypts plainText, which must already be padded.
+func (c *CBC) Encrypt(plainText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+
+	if len(plainText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	out := make([]byte, len(plainText))
+	cbcEncrypt(c.block, append([]byte(nil), c.iv...), out, plainText)
+	return out, nil
+}
+
+// cbcEncrypt encrypts src into dst, chaining on from iv, which is left
+// holding the last block of ciphertext.
+func cbcEncrypt(b cipher.Block, iv, dst, src []byte) {
+	bs := b.BlockSize()
+	if len(src) == 0 {
+		return
+	}
+	prev := iv
+	for i := 0; i < len(src); i += bs {
+		block := dst[i : i+bs]
+		xorcipher.XORBytes(block, src[i:i+bs], prev)
+		b.Encrypt(block, block)
+		prev = block
+	}
+	copy(iv, prev)
+}
+
+// cbcDecrypt decrypts src into dst, chaining on from iv, which is left
+// holding the last block of ciphertext. It works from the last block back
+// to the first, so that each block of ciphertext is still there to be
+// XORed in even when dst and src are the same, and only the last one needs
+// saving, in saved.
+func cbcDecrypt(b cipher.Block, iv, saved, dst, src []byte) {
+	bs := b.BlockSize()
+	if len(src) == 0 {
+		return
+	}
+	copy(saved, src[len(src)-bs:])
+	for i := len(src) - bs; i > 0; i -= bs {
+		b.Decrypt(dst[i:], src[i:])
+		xorcipher.XORBytes(dst[i:i+bs], dst[i:i+bs], src[i-bs:i])
+	}
+	b.Decrypt(dst, src)
+	xorcipher.XORBytes(dst[:bs], dst[:bs], iv)
+	copy(iv, saved)
+}
+
+// checkBlocks panics in the same way as the standard library's modes when
+// CryptBlocks is called with bad buffers.
+func checkBlocks(bs int, dst, src []byte) {
+	if len(src)%bs != 0 {
+		panic("modes: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("modes: output smaller than input")
+	}
+}
+
+// DetectECB says whether any 16 byte block of in repeats, which is the
+// telltale sign of ECB.
+func DetectECB(in []byte) bool {
+	if len(in)%aes.BlockSize != 0 {
+		panic("DetectECB: length not a multiple of blockSize")
+	}
+	seen := make(map[string]struct{})
+	for i := 0; i < len(in); i += aes.BlockSize {
+		val := string(in[i : i+aes.BlockSize])
+		if _, ok := seen[val]; ok {
+			return true
+		}
+		seen[val] = struct{}{}
+	}
+	return false
+}
@@ -0,0 +1,725 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+func TestChallenge7(t *testing.T) {
+	in := testutil.DecodeBase64(t, string(testutil.ReadFile(t, "../../inputs/7.txt")))
+	b, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	testutil.FatalIfErr(t, err)
+	blockCipher := NewECB(b)
+	out, err := blockCipher.Decrypt(in)
+	testutil.FatalIfErr(t, err)
+	expected := testutil.ReadFile(t, "../../outputs/6.txt")
+	testutil.AssertEqual(t, string(expected), string(padding.UnpadPKCS7(out)))
+
+	_, err = blockCipher.Decrypt(in[:20])
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+	_, err = blockCipher.Encrypt(in[:20])
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+}
+
+func TestChallenge8(t *testing.T) {
+	all := string(testutil.ReadFile(t, "../../inputs/8.txt"))
+	for i, hs := range strings.Split(all, "\n") {
+		if DetectECB(testutil.DecodeHex(t, hs)) {
+			testutil.AssertEqual(t, 133, i+1)
+		}
+	}
+}
+
+func TestChallenge10(t *testing.T) {
+	cipherText := testutil.DecodeBase64(t, string(testutil.ReadFile(t, "../../inputs/10.txt")))
+
+	iv := make([]byte, 16)
+	b, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	testutil.FatalIfErr(t, err)
+	blockCipher := NewCBC(b, iv)
+
+	out, err := blockCipher.Decrypt(cipherText)
+	testutil.FatalIfErr(t, err)
+	plainText := testutil.ReadFile(t, "../../outputs/6.txt")
+	testutil.AssertEqual(t, plainText, padding.UnpadPKCS7(out))
+
+	// Try going the other way
+	b64CipherText := string(testutil.ReadFile(t, "../../inputs/10.txt"))
+	out, err = blockCipher.Encrypt(padding.PadPKCS7(plainText, 16))
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, strings.Replace(string(b64CipherText), "\n", "", -1), testutil.EncodeBase64(out))
+}
+
+func TestRoundTripECB(t *testing.T) {
+	plainText := testutil.ReadFile(t, "../../outputs/6.txt")
+
+	b, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	testutil.FatalIfErr(t, err)
+	blockCipher := NewECB(b)
+	out, err := blockCipher.Encrypt(padding.PadPKCS7(plainText, 16))
+	testutil.FatalIfErr(t, err)
+	expected := testutil.ReadFile(t, "../../inputs/7.txt")
+
+	// the base64 encoded version we generate has no \n formatting, so we trim that from the file read
+	// (which does contain \n for readability)
+	expectedStr := strings.Replace(string(expected), "\n", "", -1)
+	testutil.AssertEqual(t, expectedStr, testutil.EncodeBase64(out))
+}
+
+func TestChallenge18(t *testing.T) {
+	ct := testutil.DecodeBase64(t, "L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
+	key, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	testutil.FatalIfErr(t, err)
+	nonce := make([]byte, 8)
+	res, err := CTR(key, ct, nonce)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby ", string(res))
+}
+
+func TestECBBlockMode(t *testing.T) {
+	b, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	testutil.FatalIfErr(t, err)
+	plainText := padding.PadPKCS7(testutil.ReadFile(t, "../../outputs/6.txt"), 16)
+
+	var enc cipher.BlockMode = NewECBEncrypter(b)
+	testutil.AssertEqual(t, 16, enc.BlockSize())
+	cipherText := make([]byte, len(plainText))
+	enc.CryptBlocks(cipherText, plainText)
+	expected, err := NewECB(b).Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, expected, cipherText)
+
+	// in place
+	NewECBDecrypter(b).CryptBlocks(cipherText, cipherText)
+	testutil.AssertEqual(t, plainText, cipherText)
+}
+
+func TestCBCBlockMode(t *testing.T) {
+	src := random.New(detrand.New(1))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	plainText := make([]byte, 1024)
+	src.Fill(plainText)
+
+	expected := make([]byte, len(plainText))
+	cipher.NewCBCEncrypter(b, iv).CryptBlocks(expected, plainText)
+
+	// the chain carries on between calls, however the blocks are split up
+	ours := NewCBCEncrypter(b, iv)
+	cipherText := make([]byte, len(plainText))
+	for i := 0; i < len(plainText); {
+		n := 16 * (1 + src.Int(3))
+		if i+n > len(plainText) {
+			n = len(plainText) - i
+		}
+		ours.CryptBlocks(cipherText[i:i+n], plainText[i:i+n])
+		i += n
+	}
+	testutil.AssertEqual(t, expected, cipherText)
+
+	// but Encrypt always starts again from the IV
+	whole, err := NewCBC(b, iv).Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, expected, whole)
+
+	// decrypt in place, in two goes
+	dec := NewCBCDecrypter(b, iv)
+	half := len(cipherText) / 32 * 16
+	dec.CryptBlocks(cipherText[:half], cipherText[:half])
+	dec.CryptBlocks(cipherText[half:], cipherText[half:])
+	testutil.AssertEqual(t, plainText, cipherText)
+
+	theirs := make([]byte, len(expected))
+	cipher.NewCBCDecrypter(b, iv).CryptBlocks(theirs, expected)
+	testutil.AssertEqual(t, plainText, theirs)
+}
+
+func TestCTRStream(t *testing.T) {
+	src := random.New(detrand.New(2))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	nonce := make([]byte, 8)
+	src.Fill(nonce)
+	plainText := []byte("Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby")
+	expected, err := CTR(b, plainText, nonce)
+	testutil.FatalIfErr(t, err)
+
+	// the keystream carries on between calls, even part way through a block
+	s, err := NewCTRStream(b, nonce)
+	testutil.FatalIfErr(t, err)
+	cipherText := make([]byte, len(plainText))
+	for i, n := 0, 1; i < len(plainText); i, n = i+n, n+2 {
+		if i+n > len(plainText) {
+			n = len(plainText) - i
+		}
+		s.XORKeyStream(cipherText[i:i+n], plainText[i:i+n])
+	}
+	testutil.AssertEqual(t, expected, cipherText)
+
+	// a single block agrees with the standard library, since our counter
+	// starts at zero
+	iv := make([]byte, 16)
+	copy(iv, nonce)
+	theirs := make([]byte, 16)
+	cipher.NewCTR(b, iv).XORKeyStream(theirs, plainText[:16])
+	testutil.AssertEqual(t, expected[:16], theirs)
+
+	// a shorter nonce is padded with zeros, but a longer one would overlap
+	// the counter
+	short, err := CTR(b, plainText, nonce[:5])
+	testutil.FatalIfErr(t, err)
+	padded, err := CTR(b, plainText, append(append([]byte(nil), nonce[:5]...), 0, 0, 0))
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, padded, short)
+	_, err = NewCTRStream(b, make([]byte, 9))
+	testutil.AssertEqual(t, true, err != nil)
+	_, err = NewCTRCipher(b, make([]byte, 16))
+	testutil.AssertEqual(t, true, err != nil)
+}
+
+func TestCounterLayout(t *testing.T) {
+	src := random.New(detrand.New(3))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	nonce := make([]byte, 12)
+	src.Fill(nonce)
+	plainText := testutil.ReadFile(t, "../../outputs/6.txt")[:100]
+
+	// GCM starts its keystream from a 32 bit counter of 2 after the nonce
+	gcmLayout := CounterLayout{Bits: 32}
+	iv, err := CounterBlock(nonce, gcmLayout, 2)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, append(append([]byte(nil), nonce...), 0, 0, 0, 2), iv)
+	c, err := NewCounterMode(b, iv, gcmLayout)
+	testutil.FatalIfErr(t, err)
+	out, err := c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	aead, err := cipher.NewGCM(b)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, aead.Seal(nil, nonce, plainText, nil)[:len(plainText)], out)
+
+	// the challenges' layout is the 8 byte nonce, then a little endian count
+	iv, err = CounterBlock(nonce[:8], CounterLayout{Bits: 64, LittleEndian: true}, 0)
+	testutil.FatalIfErr(t, err)
+	c, err = NewCounterMode(b, iv, CounterLayout{Bits: 64, LittleEndian: true})
+	testutil.FatalIfErr(t, err)
+	out, err = c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	want, err := CTR(b, plainText, nonce[:8])
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, want, out)
+
+	// a 128 bit counter carries all the way along, like the standard
+	// library's
+	iv = testutil.DecodeHex(t, "0123456789abcdefffffffffffffffff")
+	c, err = NewCounterMode(b, iv, CounterLayout{Bits: 128})
+	testutil.FatalIfErr(t, err)
+	out, err = c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	expected := make([]byte, len(plainText))
+	cipher.NewCTR(b, iv).XORKeyStream(expected, plainText)
+	testutil.AssertEqual(t, expected, out)
+
+	_, err = CounterBlock(nonce, gcmLayout, 1<<32)
+	testutil.AssertEqual(t, true, err != nil)
+	_, err = NewCounterStream(b, iv, CounterLayout{Bits: 12})
+	testutil.AssertEqual(t, true, err != nil)
+	_, err = NewCounterStream(b, nonce, gcmLayout)
+	testutil.AssertEqual(t, true, err != nil)
+}
+
+func TestCounterWrap(t *testing.T) {
+	src := random.New(detrand.New(4))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	plainText := make([]byte, 7*16)
+
+	for _, layout := range []CounterLayout{{Bits: 8}, {Bits: 32}, {Bits: 64, LittleEndian: true}, {Bits: 128}} {
+		// six blocks of counter left
+		iv, err := CounterBlock(nil, CounterLayout{Bits: 128}, 0)
+		testutil.FatalIfErr(t, err)
+		for i := 1; i <= layout.Bits/8; i++ {
+			iv[len(iv)-i] = 0xff
+		}
+		if layout.LittleEndian {
+			iv[len(iv)-layout.Bits/8] = 0xfa
+		} else {
+			iv[len(iv)-1] = 0xfa
+		}
+
+		c, err := NewCounterMode(b, iv, layout)
+		testutil.FatalIfErr(t, err)
+		_, err = c.Encrypt(plainText[:6*16])
+		testutil.FatalIfErr(t, err)
+		_, err = c.Encrypt(plainText[:6*16+1])
+		testutil.AssertEqual(t, ErrCounterWrapped, err)
+
+		// a stream stops at the same place, without writing anything
+		s, err := NewCounterStream(b, iv, layout)
+		testutil.FatalIfErr(t, err)
+		testutil.FatalIfErr(t, s.Crypt(plainText[:90], plainText[:90]))
+		out := []byte{1, 2, 3, 4, 5, 6, 7}
+		testutil.AssertEqual(t, ErrCounterWrapped, s.Crypt(out, plainText[:7]))
+		testutil.AssertEqual(t, []byte{1, 2, 3, 4, 5, 6, 7}, out)
+		testutil.FatalIfErr(t, s.Crypt(out, plainText[:6]))
+		testutil.AssertEqual(t, ErrCounterWrapped, s.Crypt(out, plainText[:1]))
+	}
+}
+
+func TestBlockStreams(t *testing.T) {
+	src := random.New(detrand.New(5))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	big := make([]byte, 3*chunkSize+7)
+	src.Fill(big)
+
+	type mode struct {
+		cipher    Cipher
+		encrypter func() cipher.BlockMode
+		decrypter func() cipher.BlockMode
+	}
+	blockModes := map[string]mode{
+		"ECB": {NewECBCipher(b, padding.PKCS7), func() cipher.BlockMode { return NewECBEncrypter(b) }, func() cipher.BlockMode { return NewECBDecrypter(b) }},
+		"CBC": {NewCBCCipher(b, iv, padding.PKCS7), func() cipher.BlockMode { return NewCBCEncrypter(b, iv) }, func() cipher.BlockMode { return NewCBCDecrypter(b, iv) }},
+	}
+	for name, m := range blockModes {
+		t.Run(name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 15, 16, 17, 32, 100, len(big)} {
+				plainText := big[:n]
+				expected, err := m.cipher.Seal(plainText)
+				testutil.FatalIfErr(t, err)
+
+				// writers, fed in odd sized pieces
+				var buf bytes.Buffer
+				w := NewEncryptWriter(&buf, m.encrypter(), padding.PKCS7)
+				_, err = io.Copy(w, iotest.HalfReader(bytes.NewReader(plainText)))
+				testutil.FatalIfErr(t, err)
+				testutil.FatalIfErr(t, w.Close())
+				testutil.AssertEqual(t, expected, buf.Bytes())
+
+				var out bytes.Buffer
+				w = NewDecryptWriter(&out, m.decrypter(), padding.PKCS7)
+				_, err = io.Copy(w, bytes.NewReader(expected))
+				testutil.FatalIfErr(t, err)
+				testutil.FatalIfErr(t, w.Close())
+				testutil.AssertEqual(t, plainText, append([]byte{}, out.Bytes()...))
+
+				// and readers, read a byte at a time
+				got, err := io.ReadAll(iotest.OneByteReader(NewEncryptReader(bytes.NewReader(plainText), m.encrypter(), padding.PKCS7)))
+				testutil.FatalIfErr(t, err)
+				testutil.AssertEqual(t, expected, got)
+
+				got, err = io.ReadAll(NewDecryptReader(iotest.OneByteReader(bytes.NewReader(expected)), m.decrypter(), padding.PKCS7))
+				testutil.FatalIfErr(t, err)
+				testutil.AssertEqual(t, plainText, append([]byte{}, got...))
+			}
+		})
+	}
+
+	// Decrypting writes everything out as it goes, except the last block.
+	cipherText, err := blockModes["CBC"].cipher.Seal(big[:70])
+	testutil.FatalIfErr(t, err)
+	var out bytes.Buffer
+	w := NewDecryptWriter(&out, NewCBCDecrypter(b, iv), padding.PKCS7)
+	_, err = w.Write(cipherText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, big[:64], out.Bytes())
+	testutil.FatalIfErr(t, w.Close())
+	testutil.AssertEqual(t, big[:70], out.Bytes())
+	_, err = w.Write(cipherText)
+	testutil.AssertEqual(t, true, err != nil)
+
+	// Bad padding and torn ciphertext only show up at the end.
+	cipherText[len(cipherText)-17] ^= 1
+	w = NewDecryptWriter(io.Discard, NewCBCDecrypter(b, iv), padding.PKCS7)
+	_, err = w.Write(cipherText)
+	testutil.FatalIfErr(t, err)
+	var padErr *padding.Error
+	testutil.AssertEqual(t, true, errors.As(w.Close(), &padErr))
+	_, err = io.ReadAll(NewDecryptReader(bytes.NewReader(cipherText[:40]), NewCBCDecrypter(b, iv), padding.PKCS7))
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+}
+
+func TestCTRStreams(t *testing.T) {
+	src := random.New(detrand.New(6))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	layout := CounterLayout{Bits: 128}
+	plainText := make([]byte, 2*chunkSize+3)
+	src.Fill(plainText)
+	c, err := NewCounterMode(b, iv, layout)
+	testutil.FatalIfErr(t, err)
+	expected, err := c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+
+	s, err := NewCounterStream(b, iv, layout)
+	testutil.FatalIfErr(t, err)
+	var buf bytes.Buffer
+	_, err = io.Copy(NewCTRWriter(&buf, s), iotest.HalfReader(bytes.NewReader(plainText)))
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, expected, buf.Bytes())
+
+	s, err = NewCounterStream(b, iv, layout)
+	testutil.FatalIfErr(t, err)
+	got, err := io.ReadAll(NewCTRReader(bytes.NewReader(expected), s))
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, plainText, got)
+
+	// an 8 bit counter runs out after 256 blocks
+	s, err = NewCounterStream(b, make([]byte, 16), CounterLayout{Bits: 8})
+	testutil.FatalIfErr(t, err)
+	_, err = io.Copy(NewCTRWriter(io.Discard, s), bytes.NewReader(plainText))
+	testutil.AssertEqual(t, ErrCounterWrapped, err)
+}
+
+func TestCipher(t *testing.T) {
+	src := random.New(detrand.New(7))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	nonce := make([]byte, 8)
+	src.Fill(nonce)
+
+	ctr, err := NewCTRCipher(b, nonce)
+	testutil.FatalIfErr(t, err)
+	ciphers := map[string]Cipher{
+		"ECB": NewECBCipher(b, padding.PKCS7),
+		"CBC": NewCBCCipher(b, src.IV(), padding.PKCS7),
+		"CTR": ctr,
+	}
+	for name, c := range ciphers {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				plainText := bytes.Repeat([]byte{'A'}, i)
+				cipherText, err := c.Seal(plainText)
+				testutil.FatalIfErr(t, err)
+				out, err := c.Open(cipherText)
+				testutil.FatalIfErr(t, err)
+				testutil.AssertEqual(t, plainText, out)
+			}
+		})
+	}
+
+	// flipping a bit in the second to last block breaks the padding
+	cipherText, err := ciphers["CBC"].Seal([]byte("ICE ICE BABY, ICE ICE BABY!!"))
+	testutil.FatalIfErr(t, err)
+	cipherText[15] ^= 1
+	_, err = ciphers["CBC"].Open(cipherText)
+	var padErr *padding.Error
+	testutil.AssertEqual(t, true, errors.As(err, &padErr))
+
+	// as does a last block which ends in a zero
+	cipherText, err = NewECBCipher(b, padding.None).Seal([]byte("YELLOW SUBMARIN\x00"))
+	testutil.FatalIfErr(t, err)
+	_, err = ciphers["ECB"].Open(cipherText)
+	testutil.AssertEqual(t, true, errors.As(err, &padErr))
+	_, err = ciphers["ECB"].Open([]byte("YELLOW"))
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+	_, err = NewECBCipher(b, padding.None).Seal([]byte("YELLOW"))
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+}
+
+// vector is one known answer test, from a file laid out like NIST's
+// response files: a section name in square brackets, then NAME = value
+// lines, with a blank line between tests.
+type vector struct {
+	section string
+	fields  map[string]string
+}
+
+func readVectors(t *testing.T, name string) []vector {
+	t.Helper()
+	var vectors []vector
+	var section string
+	var cur map[string]string
+	for _, line := range strings.Split(string(testutil.ReadFile(t, name)), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			cur = nil
+		case strings.HasPrefix(line, "["):
+			section, cur = strings.Trim(line, "[]"), nil
+		default:
+			kv := strings.SplitN(line, " = ", 2)
+			if len(kv) != 2 {
+				t.Fatalf("%s: bad line %q", name, line)
+			}
+			if cur == nil {
+				cur = make(map[string]string)
+				vectors = append(vectors, vector{section, cur})
+			}
+			cur[kv[0]] = kv[1]
+		}
+	}
+	return vectors
+}
+
+func (v vector) hex(t *testing.T, name string) []byte {
+	t.Helper()
+	return testutil.DecodeHex(t, v.fields[name])
+}
+
+func (v vector) block(t *testing.T, name string) cipher.Block {
+	t.Helper()
+	b, err := aes.NewCipher(v.hex(t, name))
+	testutil.FatalIfErr(t, err)
+	return b
+}
+
+// checkVector encrypts and decrypts a known answer test with c.
+func checkVector(t *testing.T, v vector, c BlockCipher) {
+	t.Helper()
+	plainText, cipherText := v.hex(t, "PLAINTEXT"), v.hex(t, "CIPHERTEXT")
+	out, err := c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, cipherText, out)
+	out, err = c.Decrypt(cipherText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, plainText, out)
+}
+
+func TestSP80038A(t *testing.T) {
+	segments := map[string]int{"CFB1": 1, "CFB8": 8, "CFB128": 128}
+	for _, v := range readVectors(t, "../../inputs/sp800-38a.txt") {
+		t.Run(v.section, func(t *testing.T) {
+			b, iv := v.block(t, "KEY"), v.hex(t, "IV")
+			switch v.section {
+			case "ECB":
+				checkVector(t, v, NewECB(b))
+			case "CBC":
+				checkVector(t, v, NewCBC(b, iv))
+			case "OFB":
+				checkVector(t, v, NewOFB(b, iv))
+			case "CTR":
+				// the vectors never carry out of the last 32 bits
+				for _, bits := range []int{32, 64, 128} {
+					c, err := NewCounterMode(b, iv, CounterLayout{Bits: bits})
+					testutil.FatalIfErr(t, err)
+					checkVector(t, v, c)
+				}
+			default:
+				checkVector(t, v, NewCFB(b, iv, segments[v.section]))
+			}
+		})
+	}
+}
+
+func TestCFB(t *testing.T) {
+	src := random.New(detrand.New(8))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	plainText := make([]byte, 60)
+	src.Fill(plainText)
+
+	// 128 bit segments are the same as the standard library's CFB, however
+	// they're handled
+	expected := make([]byte, len(plainText))
+	cipher.NewCFBEncrypter(b, iv).XORKeyStream(expected, plainText)
+	out, err := NewCFB(b, iv, 128).Encrypt(plainText[:48])
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, expected[:48], out)
+
+	for _, s := range []int{1, 3, 5, 8, 24, 40, 96, 120} {
+		c := NewCFB(b, iv, s)
+		cipherText, err := c.Encrypt(plainText)
+		testutil.FatalIfErr(t, err)
+		out, err := c.Decrypt(cipherText)
+		testutil.FatalIfErr(t, err)
+		testutil.AssertEqual(t, plainText, out)
+	}
+
+	_, err = NewCFB(b, iv, 16).Encrypt(plainText[:3])
+	testutil.AssertEqual(t, ErrNotFullSegments, err)
+}
+
+func TestPCBC(t *testing.T) {
+	src := random.New(detrand.New(9))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	c := NewPCBC(b, src.IV())
+	plainText := padding.PadPKCS7(testutil.ReadFile(t, "../../outputs/6.txt"), 16)
+
+	cipherText, err := c.Encrypt(plainText)
+	testutil.FatalIfErr(t, err)
+	out, err := c.Decrypt(cipherText)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, plainText, out)
+
+	// Swapping two ciphertext blocks garbles them, but nothing after them,
+	// which is the weakness Kerberos v4 had.
+	a, z := cipherText[32:48], cipherText[48:64]
+	swapped := append(append(append([]byte(nil), cipherText[:32]...), z...), a...)
+	swapped = append(swapped, cipherText[64:]...)
+	out, err = c.Decrypt(swapped)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, plainText[:32], out[:32])
+	testutil.AssertEqual(t, false, bytes.Equal(plainText[32:64], out[32:64]))
+	testutil.AssertEqual(t, plainText[64:], out[64:])
+
+	_, err = c.Encrypt(plainText[1:])
+	testutil.AssertEqual(t, ErrNotFullBlocks, err)
+}
+
+func TestCTS(t *testing.T) {
+	src := random.New(detrand.New(10))
+	for _, v := range readVectors(t, "../../inputs/rfc3962.txt") {
+		checkVector(t, v, NewCTS(v.block(t, "KEY"), v.hex(t, "IV"), CS3))
+	}
+
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	cs1, cs2, cs3 := NewCTS(b, iv, CS1), NewCTS(b, iv, CS2), NewCTS(b, iv, CS3)
+	cbc := NewCBC(b, iv)
+	for n := 16; n <= 64; n++ {
+		plainText := make([]byte, n)
+		src.Fill(plainText)
+		var cipherTexts [][]byte
+		for _, c := range []BlockCipher{cs1, cs2, cs3} {
+			cipherText, err := c.Encrypt(plainText)
+			testutil.FatalIfErr(t, err)
+			testutil.AssertEqual(t, n, len(cipherText))
+			out, err := c.Decrypt(cipherText)
+			testutil.FatalIfErr(t, err)
+			testutil.AssertEqual(t, plainText, out)
+			cipherTexts = append(cipherTexts, cipherText)
+		}
+
+		// The variants only differ in the order of the last two blocks.
+		d := n - (n-1)/16*16
+		tail := (n - 1) / 16 * 16
+		if n > 16 {
+			tail -= 16
+		}
+		one, three := cipherTexts[0], cipherTexts[2]
+		testutil.AssertEqual(t, one[:tail], three[:tail])
+		if n > 16 {
+			testutil.AssertEqual(t, one[tail:tail+d], three[len(three)-d:])
+			testutil.AssertEqual(t, one[tail+d:], three[tail:tail+16])
+		}
+		if n%16 == 0 {
+			expected, err := cbc.Encrypt(plainText)
+			testutil.FatalIfErr(t, err)
+			testutil.AssertEqual(t, expected, cipherTexts[0])
+			testutil.AssertEqual(t, expected, cipherTexts[1])
+		} else {
+			testutil.AssertEqual(t, three, cipherTexts[1])
+		}
+	}
+
+	_, err = cs1.Encrypt([]byte("YELLOW"))
+	testutil.AssertEqual(t, ErrShortInput, err)
+}
+
+func TestSP80038E(t *testing.T) {
+	src := random.New(detrand.New(11))
+	for i, v := range readVectors(t, "../../inputs/sp800-38e.txt") {
+		sector, err := strconv.ParseUint(v.fields["SECTOR"], 16, 64)
+		testutil.FatalIfErr(t, err)
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			checkVector(t, v, NewXTS(v.block(t, "KEY1"), v.block(t, "KEY2"), sector))
+		})
+	}
+
+	data, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	tweak, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	c := NewXTS(data, tweak, 42)
+	plainText := testutil.ReadFile(t, "../../outputs/6.txt")
+	for n := 16; n < 100; n++ {
+		cipherText, err := c.Encrypt(plainText[:n])
+		testutil.FatalIfErr(t, err)
+		out, err := c.Decrypt(cipherText)
+		testutil.FatalIfErr(t, err)
+		testutil.AssertEqual(t, plainText[:n], out)
+	}
+
+	// the same data in another sector encrypts differently
+	one, err := c.Encrypt(plainText[:32])
+	testutil.FatalIfErr(t, err)
+	other, err := NewXTS(data, tweak, 43).Encrypt(plainText[:32])
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, false, bytes.Equal(one, other))
+
+	_, err = c.Encrypt(plainText[:15])
+	testutil.AssertEqual(t, ErrShortInput, err)
+}
+
+func TestNoAllocs(t *testing.T) {
+	src := random.New(detrand.New(12))
+	b, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(t, err)
+	iv := src.IV()
+	buf := make([]byte, 1024)
+
+	enc, dec := NewCBCEncrypter(b, iv), NewCBCDecrypter(b, iv)
+	s, err := NewCounterStream(b, iv, CounterLayout{Bits: 32})
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, 0.0, testing.AllocsPerRun(10, func() {
+		enc.CryptBlocks(buf, buf)
+		dec.CryptBlocks(buf, buf)
+		testutil.FatalIfErr(t, s.Crypt(buf, buf[:1000]))
+	}))
+}
+
+func benchmarkBlockMode(b *testing.B, mode cipher.BlockMode) {
+	buf := make([]byte, 4096)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mode.CryptBlocks(buf, buf)
+	}
+}
+
+func benchmarkStream(b *testing.B, s cipher.Stream) {
+	buf := make([]byte, 4096)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.XORKeyStream(buf, buf)
+	}
+}
+
+// The standard library's modes are there to compare with. They use
+// assembly where they can, so they should win.
+func BenchmarkModes(b *testing.B) {
+	src := random.New(detrand.New(13))
+	block, err := aes.NewCipher(src.Key())
+	testutil.FatalIfErr(b, err)
+	iv := src.IV()
+
+	b.Run("CBCEncrypt", func(b *testing.B) { benchmarkBlockMode(b, NewCBCEncrypter(block, iv)) })
+	b.Run("CBCEncrypt/stdlib", func(b *testing.B) { benchmarkBlockMode(b, cipher.NewCBCEncrypter(block, iv)) })
+	b.Run("CBCDecrypt", func(b *testing.B) { benchmarkBlockMode(b, NewCBCDecrypter(block, iv)) })
+	b.Run("CBCDecrypt/stdlib", func(b *testing.B) { benchmarkBlockMode(b, cipher.NewCBCDecrypter(block, iv)) })
+	b.Run("CTR", func(b *testing.B) {
+		s, err := NewCounterStream(block, iv, CounterLayout{Bits: 128})
+		testutil.FatalIfErr(b, err)
+		benchmarkStream(b, s)
+	})
+	b.Run("CTR/stdlib", func(b *testing.B) { benchmarkStream(b, cipher.NewCTR(block, iv)) })
+	b.Run("CTR/challenge18", func(b *testing.B) {
+		in := make([]byte, 4096)
+		b.SetBytes(int64(len(in)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CTR(block, in, iv[:8])
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package modes
+
+import (
+	"crypto/cipher"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// OFB is a block cipher in output feedback mode. The IV is encrypted over
+// and over to make a keystream, which doesn't depend on the message at
+// all, so it works on messages of any length and encryption and decryption
+// are the same operation.
+type OFB struct {
+	block cipher.Block
+	iv    []byte
+}
+
+// NewOFB returns a BlockCipher which uses b in OFB mode with the given IV.
+func NewOFB(b cipher.Block, iv []byte) BlockCipher {
+	if len(iv) != b.BlockSize() {
+		panic("NewOFB: IV length must equal block size")
+	}
+	return &OFB{
+		block: b,
+		iv:    append([]byte(nil), iv...),
+	}
+}
+
+// Decrypt decrypts cipherText.
+func (c *OFB) Decrypt(cipherText []byte) ([]byte, error) {
+	return c.crypt(cipherText), nil
+}
+
+// Encrypt encrypts plainText.
+func (c *OFB) Encrypt(plainText []byte) ([]byte, error) {
+	return c.crypt(plainText), nil
+}
+
+func (c *OFB) crypt(in []byte) []byte {
+	bs := c.block.BlockSize()
+	out := make([]byte, len(in))
+	keystream := append([]byte(nil), c.iv...)
+	for i := 0; i < len(in); i += bs {
+		c.block.Encrypt(keystream, keystream)
+		xorcipher.XORBytes(out[i:], in[i:], keystream)
+	}
+	return out
+}
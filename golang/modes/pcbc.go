@@ -0,0 +1,65 @@
+package modes
+
+import (
+	"crypto/cipher"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// PCBC is a block cipher in propagating cipher block chaining mode, as
+// used by Kerberos v4 and WASTE. Each block is XORed with both the
+// plaintext and the ciphertext of the block before it, so unlike CBC, an
+// error in one block garbles every block after it. Swapping two adjacent
+// ciphertext blocks doesn't, though, which is how Kerberos v4 came unstuck.
+type PCBC struct {
+	block cipher.Block
+	iv    []byte
+}
+
+// NewPCBC returns a BlockCipher which uses b in PCBC mode with the given
+// IV.
+func NewPCBC(b cipher.Block, iv []byte) BlockCipher {
+	if len(iv) != b.BlockSize() {
+		panic("NewPCBC: IV length must equal block size")
+	}
+	return &PCBC{
+		block: b,
+		iv:    append([]byte(nil), iv...),
+	}
+}
+
+// Decrypt decrypts cipherText, leaving any padding in place.
+func (c *PCBC) Decrypt(cipherText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(cipherText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	plainText := make([]byte, len(cipherText))
+	chain := append([]byte(nil), c.iv...)
+	for i := 0; i < len(cipherText); i += bs {
+		block := plainText[i : i+bs]
+		c.block.Decrypt(block, cipherText[i:])
+		xorcipher.XORBytes(block, block, chain)
+		xorcipher.XORBytes(chain, block, cipherText[i:i+bs])
+	}
+	return plainText, nil
+}
+
+// Encrypt encrypts plainText, which must already be padded.
+func (c *PCBC) Encrypt(plainText []byte) ([]byte, error) {
+	bs := c.block.BlockSize()
+	if len(plainText)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+
+	cipherText := make([]byte, len(plainText))
+	chain := append([]byte(nil), c.iv...)
+	for i := 0; i < len(plainText); i += bs {
+		block := cipherText[i : i+bs]
+		xorcipher.XORBytes(block, plainText[i:i+bs], chain)
+		c.block.Encrypt(block, block)
+		xorcipher.XORBytes(chain, plainText[i:i+bs], block)
+	}
+	return cipherText, nil
+}
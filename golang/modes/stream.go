@@ -0,0 +1,264 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+// chunkSize is roughly how much the streaming readers and writers hold
+// onto at once, whatever the size of the message.
+const chunkSize = 32 * 1024
+
+// errClosed is returned by writes to a writer which has been closed.
+var errClosed = errors.New("modes: writer is closed")
+
+// blockStream is the part of streaming through a cipher.BlockMode which
+// readers and writers share: working out how much can be done so far, and
+// dealing with the padding at the end.
+type blockStream struct {
+	mode    cipher.BlockMode
+	padding padding.Scheme
+	decrypt bool
+	buf     []byte
+}
+
+func newBlockStream(mode cipher.BlockMode, p padding.Scheme, decrypt bool) blockStream {
+	bs := mode.BlockSize()
+	// Leave room for a block of padding on the end of a full buffer.
+	n := (chunkSize/bs + 1) * bs
+	return blockStream{
+		mode:    mode,
+		padding: p,
+		decrypt: decrypt,
+		buf:     make([]byte, 0, n+bs),
+	}
+}
+
+// crypt encrypts or decrypts as much of the buffer as it can before the end
+// of the message is known, and returns how much that was. That's all the
+// whole blocks, except that decrypting holds back the last whole block if
+// nothing comes after it yet, since it could hold the padding.
+func (s *blockStream) crypt() int {
+	bs := s.mode.BlockSize()
+	n := len(s.buf) / bs * bs
+	if s.decrypt && n == len(s.buf) {
+		n -= bs
+	}
+	if n <= 0 {
+		return 0
+	}
+	s.mode.CryptBlocks(s.buf[:n], s.buf[:n])
+	return n
+}
+
+// finish deals with whatever is left in the buffer at the end of the
+// message, adding or taking off the padding, and returns the result.
+func (s *blockStream) finish() ([]byte, error) {
+	bs := s.mode.BlockSize()
+	if s.decrypt {
+		if len(s.buf)%bs != 0 {
+			return nil, ErrNotFullBlocks
+		}
+		s.mode.CryptBlocks(s.buf, s.buf)
+		out, err := s.padding.Unpad(s.buf, bs)
+		if err != nil {
+			return nil, err
+		}
+		return s.buf[:len(out)], nil
+	}
+
+	whole := len(s.buf) / bs * bs
+	s.buf = append(s.buf[:whole], s.padding.Pad(s.buf[whole:], bs)...)
+	if len(s.buf)%bs != 0 {
+		return nil, ErrNotFullBlocks
+	}
+	s.mode.CryptBlocks(s.buf, s.buf)
+	return s.buf, nil
+}
+
+// shift throws away the first n bytes of the buffer.
+func (s *blockStream) shift(n int) {
+	s.buf = s.buf[:copy(s.buf, s.buf[n:])]
+}
+
+// blockWriter encrypts or decrypts what's written to it with a
+// cipher.BlockMode, and writes the result to w.
+type blockWriter struct {
+	blockStream
+	w   io.Writer
+	err error
+}
+
+// NewEncryptWriter returns a writer which encrypts everything written to
+// it with mode, and writes the ciphertext to w as it goes. Close pads and
+// writes the last block, but doesn't close w. It only ever holds onto a
+// few kilobytes, so it can encrypt files of any size.
+func NewEncryptWriter(w io.Writer, mode cipher.BlockMode, p padding.Scheme) io.WriteCloser {
+	return &blockWriter{blockStream: newBlockStream(mode, p, false), w: w}
+}
+
+// NewDecryptWriter returns a writer which decrypts everything written to it
+// with mode, and writes the plaintext to w as it goes. The last block is
+// held back until Close, which checks and strips the padding, and returns
+// a *padding.Error if it's bad.
+func NewDecryptWriter(w io.Writer, mode cipher.BlockMode, p padding.Scheme) io.WriteCloser {
+	return &blockWriter{blockStream: newBlockStream(mode, p, true), w: w}
+}
+
+func (bw *blockWriter) Write(p []byte) (int, error) {
+	written := 0
+	for bw.err == nil && len(p) > 0 {
+		n := copy(bw.buf[len(bw.buf):cap(bw.buf)-bw.mode.BlockSize()], p)
+		bw.buf = bw.buf[:len(bw.buf)+n]
+		p = p[n:]
+		written += n
+
+		if n := bw.crypt(); n > 0 {
+			_, bw.err = bw.w.Write(bw.buf[:n])
+			bw.shift(n)
+		}
+	}
+	return written, bw.err
+}
+
+// Close finishes off the message. It's an error to write any more
+// afterwards.
+func (bw *blockWriter) Close() error {
+	if bw.err != nil {
+		return bw.err
+	}
+	out, err := bw.finish()
+	if err == nil {
+		_, err = bw.w.Write(out)
+	}
+	bw.err = err
+	if bw.err == nil {
+		bw.err = errClosed
+	}
+	return err
+}
+
+// blockReader encrypts or decrypts what it reads from r with a
+// cipher.BlockMode.
+type blockReader struct {
+	blockStream
+	r    io.Reader
+	done int
+	out  []byte
+	err  error
+}
+
+// NewEncryptReader returns a reader which reads plaintext from r and
+// returns it encrypted with mode, padding it when r runs out. Like the
+// writers, it works a chunk at a time, so r can be as big as you like.
+func NewEncryptReader(r io.Reader, mode cipher.BlockMode, p padding.Scheme) io.Reader {
+	return &blockReader{blockStream: newBlockStream(mode, p, false), r: r}
+}
+
+// NewDecryptReader returns a reader which reads ciphertext from r and
+// returns it decrypted with mode. It has to read a block ahead, so that it
+// can strip the padding from the last one, and it returns a *padding.Error
+// instead of io.EOF if the padding is bad.
+func NewDecryptReader(r io.Reader, mode cipher.BlockMode, p padding.Scheme) io.Reader {
+	return &blockReader{blockStream: newBlockStream(mode, p, true), r: r}
+}
+
+func (br *blockReader) Read(p []byte) (int, error) {
+	for len(br.out) == 0 {
+		if br.err != nil {
+			return 0, br.err
+		}
+		br.err = br.fill()
+	}
+	n := copy(p, br.out)
+	br.out = br.out[n:]
+	return n, nil
+}
+
+// fill reads from r until there's some output to hand back, or r runs out.
+// It returns io.EOF once the last of the output is ready.
+func (br *blockReader) fill() error {
+	// Whatever was handed out last time is finished with.
+	br.shift(br.done)
+	br.done = 0
+	for {
+		n, err := br.r.Read(br.buf[len(br.buf) : cap(br.buf)-br.mode.BlockSize()])
+		br.buf = br.buf[:len(br.buf)+n]
+		if err == io.EOF {
+			out, err := br.finish()
+			if err != nil {
+				return err
+			}
+			br.out = out
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if n := br.crypt(); n > 0 {
+			br.done = n
+			br.out = br.buf[:n]
+			return nil
+		}
+	}
+}
+
+// ctrReader decrypts or encrypts what it reads from r with a CTRStream.
+type ctrReader struct {
+	r io.Reader
+	s *CTRStream
+}
+
+// NewCTRReader returns a reader which reads from r and XORs it with the
+// keystream from s. Unlike cipher.StreamReader, it returns
+// ErrCounterWrapped rather than panicking if the counter runs out.
+func NewCTRReader(r io.Reader, s *CTRStream) io.Reader {
+	return &ctrReader{r: r, s: s}
+}
+
+func (cr *ctrReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	if cerr := cr.s.Crypt(p[:n], p[:n]); cerr != nil {
+		return 0, cerr
+	}
+	return n, err
+}
+
+// ctrWriter decrypts or encrypts what's written to it with a CTRStream,
+// and writes the result to w.
+type ctrWriter struct {
+	w   io.Writer
+	s   *CTRStream
+	buf []byte
+}
+
+// NewCTRWriter returns a writer which XORs everything written to it with
+// the keystream from s, and writes the result to w. It returns
+// ErrCounterWrapped rather than panicking if the counter runs out, and
+// never needs closing, since CTR has no padding.
+func NewCTRWriter(w io.Writer, s *CTRStream) io.Writer {
+	return &ctrWriter{w: w, s: s, buf: make([]byte, chunkSize)}
+}
+
+func (cw *ctrWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > len(cw.buf) {
+			n = len(cw.buf)
+		}
+		if err := cw.s.Crypt(cw.buf, p[:n]); err != nil {
+			return written, err
+		}
+		m, err := cw.w.Write(cw.buf[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -0,0 +1,112 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/xorcipher"
+)
+
+// XTS is a block cipher in XTS mode, as used for disk encryption. Each
+// block is encrypted as E(P ⊕ T) ⊕ T, where the tweak T starts as the
+// sector number encrypted under a second key, and is multiplied by x in
+// GF(2^128) from one block to the next. Sectors which aren't a whole number
+// of blocks steal ciphertext from the second to last block.
+type XTS struct {
+	data   cipher.Block
+	tweak  cipher.Block
+	sector uint64
+}
+
+// NewXTS returns a BlockCipher which uses data in XTS mode for the given
+// sector (the data unit sequence number, in IEEE 1619), with tweak
+// encrypting the sector number. The two should have different keys, and
+// both need 16 byte blocks. Every message is one sector, and has to be at
+// least one block long.
+func NewXTS(data, tweak cipher.Block, sector uint64) BlockCipher {
+	if data.BlockSize() != 16 || tweak.BlockSize() != 16 {
+		panic("NewXTS: block size must be 16")
+	}
+	return &XTS{
+		data:   data,
+		tweak:  tweak,
+		sector: sector,
+	}
+}
+
+// Decrypt decrypts cipherText.
+func (c *XTS) Decrypt(cipherText []byte) ([]byte, error) {
+	return c.crypt(cipherText, true)
+}
+
+// Encrypt encrypts plainText.
+func (c *XTS) Encrypt(plainText []byte) ([]byte, error) {
+	return c.crypt(plainText, false)
+}
+
+func (c *XTS) crypt(in []byte, decrypt bool) ([]byte, error) {
+	const bs = 16
+	if len(in) < bs {
+		return nil, ErrShortInput
+	}
+
+	t := make([]byte, bs)
+	binary.LittleEndian.PutUint64(t, c.sector)
+	c.tweak.Encrypt(t, t)
+
+	out := make([]byte, len(in))
+	full := len(in) / bs
+	partial := len(in) % bs
+	if partial != 0 {
+		// The last whole block is dealt with along with the partial one.
+		full--
+	}
+	for i := 0; i < full; i++ {
+		c.cryptBlock(out[i*bs:], in[i*bs:], t, decrypt)
+		mulX(t)
+	}
+	if partial == 0 {
+		return out, nil
+	}
+
+	// Encrypting, the last whole block uses this tweak and the block made
+	// from the partial one and its stolen ciphertext uses the next one.
+	// Decrypting has to undo them in the opposite order.
+	m := full * bs
+	first, second := t, append([]byte(nil), t...)
+	mulX(second)
+	if decrypt {
+		first, second = second, first
+	}
+	cc := make([]byte, bs)
+	c.cryptBlock(cc, in[m:], first, decrypt)
+	copy(out[m+bs:], cc[:partial])
+	copy(cc, in[m+bs:])
+	c.cryptBlock(out[m:], cc, second, decrypt)
+	return out, nil
+}
+
+// cryptBlock encrypts or decrypts one block of src into dst, with tweak t.
+func (c *XTS) cryptBlock(dst, src, t []byte, decrypt bool) {
+	xorcipher.XORBytes(dst, src[:len(t)], t)
+	if decrypt {
+		c.data.Decrypt(dst, dst)
+	} else {
+		c.data.Encrypt(dst, dst)
+	}
+	xorcipher.XORBytes(dst, dst[:len(t)], t)
+}
+
+// mulX multiplies t by x in GF(2^128), reduced by x^128 + x^7 + x^2 + x + 1.
+// XTS stores the field elements little endian, unlike GCM.
+func mulX(t []byte) {
+	var carry byte
+	for i := range t {
+		next := t[i] >> 7
+		t[i] = t[i]<<1 | carry
+		carry = next
+	}
+	if carry != 0 {
+		t[0] ^= 0x87
+	}
+}
@@ -0,0 +1,95 @@
+package oracle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+// NewCBCCookie returns a pair of functions sharing a random key: one
+// which wraps some user data in a CBC encrypted cookie, and one which
+// decrypts a cookie and says whether it makes the user an admin.
+func NewCBCCookie(rand io.Reader) (
+	generateCookie func(string) string,
+	amIAdmin func(string) bool,
+) {
+	// Generate a random AES key.
+	src := random.New(rand)
+	b, _ := aes.NewCipher(src.Key())
+	c := modes.NewCBCCipher(b, src.IV(), padding.PKCS7)
+
+	generateCookie = func(userdata string) string {
+		// The function should quote out the ";" and "=" characters.
+		userdata = strings.Replace(userdata, ";", "%3B", -1)
+		userdata = strings.Replace(userdata, "=", "%3D", -1)
+
+		// The first function should take an arbitrary input string, prepend the string:
+		//
+		// "comment1=cooking%20MCs;userdata="
+		//
+		// and append the string:
+		//
+		// ";comment2=%20like%20a%20pound%20of%20bacon"
+		msg := "comment1=cooking%20MCs;userdata=" + userdata + ";comment2=%20like%20a%20pound%20of%20bacon"
+
+		// The function should then pad out the input to the 16-byte AES block length and encrypt it under the random AES key.
+		out, _ := c.Seal([]byte(msg))
+		return string(out)
+	}
+
+	amIAdmin = func(in string) bool {
+		// The second function should decrypt the string
+		out, err := c.Open([]byte(in))
+		if err != nil {
+			return false
+		}
+		// and look for the characters ";role=admin;"
+		return bytes.Contains(out, []byte(";role=admin;"))
+	}
+
+	return
+}
+
+// NewCBCPadding returns a pair of functions sharing a random key: one which
+// encrypts plainText under CBC, and one which decrypts a ciphertext and
+// says whether its padding is valid.
+func NewCBCPadding(rand io.Reader, plainText []byte) (
+	encrypt func() []byte,
+	isValidPadding func([]byte) bool,
+) {
+	src := random.New(rand)
+	b, _ := aes.NewCipher(src.Key())
+	iv := src.IV()
+	c := modes.NewCBCCipher(b, iv, padding.PKCS7)
+
+	encrypt = func() []byte {
+		res, err := c.Seal(plainText)
+		if err != nil {
+			panic(err)
+		}
+		// See how the attack works, at the start of attack.CBCPadding...
+		// We need to return the iv as the first block, and the cipher
+		// text as the following blocks
+		return append(iv, res...)
+	}
+
+	isValidPadding = func(in []byte) bool {
+		_, err := c.Open(in)
+		var padErr *padding.Error
+		if errors.As(err, &padErr) {
+			return false
+		}
+		if err != nil {
+			panic(err)
+		}
+		return true
+	}
+
+	return
+}
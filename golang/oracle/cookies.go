@@ -1,10 +1,12 @@
-package main
+package oracle
 
 import "strings"
 
+// Cookies are the key-value pairs in a user profile.
 type Cookies map[string]string
 
-func parseKeyValuePairs(v string) Cookies {
+// ParseKeyValuePairs parses a string like "foo=bar&baz=qux".
+func ParseKeyValuePairs(v string) Cookies {
 	// TOOD(jabley): defend against bad input
 	res := make(map[string]string)
 	pairs := strings.Split(v, "&")
@@ -30,6 +32,7 @@ func parseKeyValuePairs(v string) Cookies {
 	return res
 }
 
+// String encodes c, with email, uid and role in that order.
 func (c *Cookies) String() string {
 	tmp := make(Cookies)
 
@@ -58,6 +61,8 @@ func (c *Cookies) String() string {
 	return strings.Join(res, "&")
 }
 
+// ProfileFor returns the encoded profile for a user with the given email
+// address, stripping out any metacharacters.
 func ProfileFor(email string) string {
 	sanitised := strings.Replace(strings.Replace(email, "&", "", -1), "=", "", -1)
 	cookies := make(Cookies)
@@ -0,0 +1,88 @@
+// Package oracle holds the servers the challenges attack: functions which
+// encrypt, decrypt or check things under a key the attacker doesn't know.
+package oracle
+
+import (
+	"crypto/aes"
+	"io"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/modes"
+	"github.com/jabley/matasano-crypto-challenges/golang/padding"
+)
+
+// EncryptionOracle is a function signature for a closure which
+// encrypts content consistently. We want to be able to reuse
+// an oracle when trying to brute-force it.
+type EncryptionOracle func([]byte) ([]byte, modes.Mode)
+
+// NewRandomMode returns an oracle which encrypts under a random key, using
+// ECB half the time and CBC the other half, and surrounds its input with a
+// few random bytes first. All of that randomness comes from rand, which is
+// crypto/rand if it's nil, as it is for all the oracles.
+func NewRandomMode(rand io.Reader) EncryptionOracle {
+	src := random.New(rand)
+
+	// generate a random key and encrypt under it.
+	b, _ := aes.NewCipher(src.Key())
+
+	var mode modes.Mode
+	var c modes.Cipher
+	// choose to encrypt under ECB 1/2 the time, and under CBC the other half
+	if src.Int(2) == 0 {
+		mode = modes.ModeECB
+		c = modes.NewECBCipher(b, padding.PKCS7)
+	} else {
+		mode = modes.ModeCBC
+		// just use random IVs each time for CBC
+		c = modes.NewCBCCipher(b, src.IV(), padding.PKCS7)
+	}
+
+	return func(plainText []byte) ([]byte, modes.Mode) {
+		// append 5-10 bytes (count chosen randomly) before the plaintext and
+		// 5-10 bytes after the plaintext.
+		randomPrefix := src.Bytes(6)
+		randomSuffix := src.Bytes(6)
+
+		plainText = append(append(randomPrefix, plainText...), randomSuffix...)
+		cipherText, err := c.Seal(plainText)
+		if err != nil {
+			panic(err)
+		}
+		return cipherText, mode
+	}
+}
+
+// NewECBSuffix returns an oracle which appends secret to its input and
+// encrypts the lot under ECB with a random key.
+func NewECBSuffix(rand io.Reader, secret []byte) EncryptionOracle {
+	b, _ := aes.NewCipher(random.New(rand).Key())
+	c := modes.NewECBCipher(b, padding.PKCS7)
+
+	return func(in []byte) ([]byte, modes.Mode) {
+		out, err := c.Seal(append(in, secret...))
+		if err != nil {
+			panic(err)
+		}
+		return out, modes.ModeECB
+	}
+}
+
+// NewECBSuffixWithPrefix is like NewECBSuffix, but also puts a random
+// prefix of a fixed length in front of the input.
+func NewECBSuffixWithPrefix(rand io.Reader, secret []byte) EncryptionOracle {
+	src := random.New(rand)
+	b, _ := aes.NewCipher(src.Key())
+	c := modes.NewECBCipher(b, padding.PKCS7)
+
+	prefix := make([]byte, src.Int(100))
+
+	return func(in []byte) ([]byte, modes.Mode) {
+		src.Fill(prefix)
+		out, err := c.Seal(append(prefix, append(in, secret...)...))
+		if err != nil {
+			panic(err)
+		}
+		return out, modes.ModeECB
+	}
+}
@@ -0,0 +1,23 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestParseKeyValuePairs(t *testing.T) {
+	testutil.AssertEqual(t, ParseKeyValuePairs("foo=bar&baz=qux&zap=zazzle"), Cookies{
+		"foo": "bar",
+		"baz": "qux",
+		"zap": "zazzle",
+	})
+	testutil.AssertEqual(t, ParseKeyValuePairs("email=foo@bar.com&role=admin&role=user"), Cookies{
+		"email": "foo@bar.com",
+		"role":  "admin",
+	})
+}
+
+func TestProfileFor(t *testing.T) {
+	testutil.AssertEqual(t, "email=foo@bar.comroleadmin&uid=10&role=user", ProfileFor("foo@bar.com&role=admin"))
+}
@@ -0,0 +1,74 @@
+// Package padding pads plaintexts out to a whole number of blocks.
+package padding
+
+// PadPKCS7 is a padding scheme that returns a padded plaintext array that is
+// an even multiple of the blocksize
+func PadPKCS7(in []byte, blockSize int) []byte {
+	checkBlockSize(blockSize)
+
+	padLen := blockSize - len(in)%blockSize
+	res := make([]byte, len(in)+padLen)
+	n := copy(res, in)
+	for i := 0; i < padLen; i++ {
+		res[n+i] = byte(padLen)
+	}
+	return res
+}
+
+// checkBlockSize panics unless the padding length can be held in a byte.
+// A block size of 256 would need a pad byte of 256, which wraps around to
+// 0.
+func checkBlockSize(blockSize int) {
+	if blockSize < 1 {
+		panic("size can't be less than 1")
+	}
+
+	if blockSize > 255 {
+		panic("size can't be greater than max byte")
+	}
+}
+
+// IsPKCS7Padded says whether buf ends with valid PKCS#7 padding for the
+// block size bs.
+func IsPKCS7Padded(buf []byte, bs int) bool {
+	n := len(buf)
+	if n == 0 {
+		return false
+	}
+
+	// Read the last byte
+	padding := buf[n-1]
+
+	// Check that it's a positive int less than or equal to block size
+	if !(int(padding) > 0 && int(padding) <= bs) {
+		return false
+	}
+
+	if n-int(padding) < 0 {
+		return false
+	}
+
+	for _, b := range buf[n-int(padding):] {
+		if b != padding {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnpadPKCS7 strips PKCS#7 padding from plainText, without checking it.
+// Use PKCS7.Unpad to check it.
+func UnpadPKCS7(plainText []byte) []byte {
+	n := len(plainText)
+	if n == 0 {
+		return plainText
+	}
+	paddingLength := int(plainText[n-1])
+
+	if n-paddingLength < 0 {
+		return plainText
+	}
+
+	return plainText[:n-paddingLength]
+}
@@ -0,0 +1,160 @@
+package padding
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge9(t *testing.T) {
+	out := PadPKCS7([]byte("YELLOW SUBMARINE"), 20)
+	testutil.AssertEqual(t, []byte("YELLOW SUBMARINE\x04\x04\x04\x04"), out)
+}
+
+func TestChallenge15(t *testing.T) {
+	testutil.AssertEqual(t, true, IsPKCS7Padded([]byte("ICE ICE BABY\x04\x04\x04\x04"), 16))
+	testutil.AssertEqual(t, "ICE ICE BABY", string(UnpadPKCS7([]byte("ICE ICE BABY\x04\x04\x04\x04"))))
+	testutil.AssertEqual(t, false, IsPKCS7Padded([]byte("ICE ICE BABY\x05\x05\x05\x05"), 16))
+	testutil.AssertEqual(t, false, IsPKCS7Padded([]byte("ICE ICE BABY\x01\x02\x03\x04"), 16))
+}
+
+func TestPadPKCS7(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		t.Run(fmt.Sprintf("input size %2d", i), func(t *testing.T) {
+			buf := bytes.Repeat([]byte{byte('A')}, i)
+			padded := PadPKCS7(buf, 16)
+			testutil.AssertEqual(t, len(padded), 16)
+		})
+	}
+
+	buf := bytes.Repeat([]byte{byte('A')}, 16)
+	padded := PadPKCS7(buf, 16)
+	testutil.AssertEqual(t, len(padded), 32)
+}
+
+func TestUnpadPKCS7(t *testing.T) {
+	testutil.AssertEqual(t, IsPKCS7Padded(nil, 16), false)
+	testutil.AssertEqual(t, IsPKCS7Padded([]byte{}, 16), false)
+
+	for i := 0; i < 34; i++ {
+		t.Run(fmt.Sprintf("input size %2d bytes", i), func(t *testing.T) {
+			testutil.AssertEqual(t, IsPKCS7Padded(PadPKCS7(bytes.Repeat([]byte{0x01}, i), 16), 16), true)
+		})
+	}
+}
+
+func TestPKCS7Scheme(t *testing.T) {
+	for i := 0; i < 34; i++ {
+		in := bytes.Repeat([]byte{'A'}, i)
+		out, err := PKCS7.Unpad(PKCS7.Pad(in, 16), 16)
+		testutil.FatalIfErr(t, err)
+		testutil.AssertEqual(t, in, out)
+	}
+
+	for _, in := range []string{
+		"",
+		"ICE ICE BABY\x04\x04\x04",
+		"ICE ICE BABY\x05\x05\x05\x05",
+		"ICE ICE BABY\x01\x02\x03\x04",
+		"ICE ICE BABY1234",
+		"ICE ICE BABY\x00\x00\x00\x00",
+	} {
+		_, err := PKCS7.Unpad([]byte(in), 16)
+		_, ok := err.(*Error)
+		testutil.AssertEqual(t, true, ok)
+	}
+
+	out, err := None.Unpad(None.Pad([]byte("YELLOW SUBMARINE"), 16), 16)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, "YELLOW SUBMARINE", string(out))
+}
+
+func TestPadPKCS7BlockSize(t *testing.T) {
+	for _, size := range []int{0, 256} {
+		func() {
+			defer func() {
+				testutil.AssertEqual(t, true, recover() != nil)
+			}()
+			PadPKCS7([]byte("YELLOW SUBMARINE"), size)
+		}()
+	}
+	testutil.AssertEqual(t, byte(255), PadPKCS7(nil, 255)[254])
+	testutil.AssertEqual(t, 0, len(UnpadPKCS7(nil)))
+}
+
+func TestSchemes(t *testing.T) {
+	dd := []byte{0xdd, 0xdd, 0xdd, 0xdd}
+	for _, tc := range []struct {
+		scheme Scheme
+		padded string
+	}{
+		{PKCS7, "dddddddd04040404"},
+		{PKCS5, "dddddddd04040404"},
+		{ANSIX923, "dddddddd00000004"},
+		{ISO7816, "dddddddd80000000"},
+		{Zero, "dddddddd00000000"},
+	} {
+		testutil.AssertEqual(t, tc.padded, fmt.Sprintf("%x", tc.scheme.Pad(dd, 8)))
+	}
+	testutil.AssertEqual(t, byte(4), ISO10126.Pad(dd, 8)[7])
+	testutil.AssertEqual(t, NewISO10126(detrand.New(1)).Pad(dd, 8), NewISO10126(detrand.New(1)).Pad(dd, 8))
+
+	for name, s := range byName {
+		t.Run(name, func(t *testing.T) {
+			bs := 16
+			if s == PKCS5 {
+				bs = 8
+			}
+			for i := 0; i < 2*bs+2; i++ {
+				if s == None && i%bs != 0 {
+					continue
+				}
+				in := bytes.Repeat([]byte{'A'}, i)
+				padded := s.Pad(in, bs)
+				testutil.AssertEqual(t, 0, len(padded)%bs)
+				out, err := s.Unpad(padded, bs)
+				testutil.FatalIfErr(t, err)
+				testutil.AssertEqual(t, in, out)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		scheme Scheme
+		in     string
+	}{
+		{PKCS7, ""},
+		{PKCS7, "ICE ICE BABY\x04\x04\x04"},
+		{PKCS7, "ICE ICE BABY\x05\x05\x05\x05"},
+		{PKCS7, "ICE ICE BABY\x01\x02\x03\x04"},
+		{PKCS7, "ICE ICE BABY\x00\x00\x00\x00"},
+		{PKCS7, "ICE ICE BABY\x04\x04\x04\x11"},
+		{PKCS5, "ICE ICE BABY\x04\x04\x04\x04"},
+		{ANSIX923, "ICE ICE BABY\x00\x01\x00\x04"},
+		{ANSIX923, "ICE ICE BABY\x00\x00\x00\x00"},
+		{ISO10126, "ICE ICE BABY\x00\x00\x00\x00"},
+		{ISO10126, "ICE ICE BABY\x00\x00\x00\x20"},
+		{ISO7816, "ICE ICE BABY\x00\x00\x00\x00"},
+		{ISO7816, "ICE ICE BABY\x80\x00\x00\x01"},
+		{ISO7816, "ICE ICE BABY\x80\x00\x00"},
+		{Zero, "ICE ICE BABY\x00\x00\x00"},
+	} {
+		_, err := tc.scheme.Unpad([]byte(tc.in), 16)
+		_, ok := err.(*Error)
+		testutil.AssertEqual(t, true, ok)
+	}
+
+	// the zero padding of a block of zeros keeps one of them
+	out, err := Zero.Unpad(make([]byte, 16), 16)
+	testutil.FatalIfErr(t, err)
+	testutil.AssertEqual(t, 1, len(out))
+
+	s, ok := ByName("ANSIX923")
+	testutil.AssertEqual(t, true, ok)
+	testutil.AssertEqual(t, ANSIX923, s)
+	_, ok = ByName("rot13")
+	testutil.AssertEqual(t, false, ok)
+}
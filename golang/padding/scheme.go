@@ -0,0 +1,256 @@
+package padding
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Scheme is a way of padding messages out to a whole number of blocks, and
+// of taking the padding off again.
+type Scheme interface {
+	// Pad returns in followed by enough padding to fill its last block.
+	Pad(in []byte, blockSize int) []byte
+	// Unpad checks the padding at the end of in, and returns what comes
+	// before it. If the padding isn't valid, the error is an *Error.
+	Unpad(in []byte, blockSize int) ([]byte, error)
+}
+
+// Error says why some padding isn't valid.
+type Error struct {
+	Scheme string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("padding: bad %s padding: %s", e.Scheme, e.Reason)
+}
+
+// The padding schemes. The ones which end with a length byte can only pad
+// to block sizes up to 255.
+var (
+	// PKCS7 is PKCS#7 padding: n bytes each holding the value n.
+	PKCS7 Scheme = pkcs7{name: "PKCS#7"}
+
+	// PKCS5 is PKCS#7 padding for 8 byte blocks, which is all PKCS#5
+	// defines it for. Plenty of libraries say PKCS#5 when they mean
+	// PKCS#7 with bigger blocks, so use PKCS7 to talk to them.
+	PKCS5 Scheme = pkcs5{}
+
+	// ANSIX923 is ANSI X9.23 padding: zeros, and then a byte holding the
+	// length of the padding.
+	ANSIX923 Scheme = ansiX923{}
+
+	// ISO10126 is ISO 10126 padding: random bytes, and then a byte
+	// holding the length of the padding. Only the length can be checked.
+	// The random bytes come from crypto/rand; NewISO10126 takes them from
+	// somewhere else.
+	ISO10126 = NewISO10126(nil)
+
+	// ISO7816 is ISO/IEC 7816-4 padding, also known as bit padding: a
+	// single 1 bit, then as many zeros as it takes. In bytes, that's 0x80
+	// followed by zeros.
+	ISO7816 Scheme = iso7816{}
+
+	// Zero pads with zeros, and adds nothing to a message which is already
+	// a whole number of blocks. Unpadding removes trailing zeros from the
+	// last block, so it can't tell them apart from zeros at the end of the
+	// message. Only use it for messages which can't end in a zero.
+	Zero Scheme = zero{}
+
+	// None is for messages which are already a whole number of blocks. It
+	// adds and removes nothing.
+	None Scheme = none{}
+)
+
+// ByName returns the scheme with the given name, ignoring case: one of
+// pkcs7, pkcs5, ansix923, iso10126, iso7816, zero or none.
+func ByName(name string) (Scheme, bool) {
+	s, ok := byName[strings.ToLower(name)]
+	return s, ok
+}
+
+var byName = map[string]Scheme{
+	"pkcs7":    PKCS7,
+	"pkcs5":    PKCS5,
+	"ansix923": ANSIX923,
+	"iso10126": ISO10126,
+	"iso7816":  ISO7816,
+	"zero":     Zero,
+	"none":     None,
+}
+
+// checkBlocks returns an error unless in is a nonempty whole number of
+// blocks, as anything padded with scheme has to be.
+func checkBlocks(scheme string, in []byte, blockSize int) error {
+	if len(in) == 0 || len(in)%blockSize != 0 {
+		return &Error{scheme, "not a whole number of blocks"}
+	}
+	return nil
+}
+
+// padLength reads and checks the length byte at the end of in.
+func padLength(scheme string, in []byte, blockSize int) (int, error) {
+	if err := checkBlocks(scheme, in, blockSize); err != nil {
+		return 0, err
+	}
+	n := int(in[len(in)-1])
+	if n == 0 || n > blockSize {
+		return 0, &Error{scheme, fmt.Sprintf("bad length %d", n)}
+	}
+	return n, nil
+}
+
+// padWithLength returns in followed by fill, which writes the first n-1
+// bytes of padding, and then the length n.
+func padWithLength(in []byte, blockSize int, fill func([]byte)) []byte {
+	checkBlockSize(blockSize)
+	n := blockSize - len(in)%blockSize
+	res := make([]byte, len(in)+n)
+	copy(res, in)
+	fill(res[len(in) : len(res)-1])
+	res[len(res)-1] = byte(n)
+	return res
+}
+
+type pkcs7 struct {
+	name string
+}
+
+func (pkcs7) Pad(in []byte, blockSize int) []byte {
+	return PadPKCS7(in, blockSize)
+}
+
+func (p pkcs7) Unpad(in []byte, blockSize int) ([]byte, error) {
+	n, err := padLength(p.name, in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range in[len(in)-n:] {
+		if int(b) != n {
+			return nil, &Error{p.name, "inconsistent padding bytes"}
+		}
+	}
+	return in[:len(in)-n], nil
+}
+
+type pkcs5 struct{}
+
+func (pkcs5) Pad(in []byte, blockSize int) []byte {
+	if blockSize != 8 {
+		panic("PKCS#5 padding needs 8 byte blocks")
+	}
+	return PadPKCS7(in, blockSize)
+}
+
+func (pkcs5) Unpad(in []byte, blockSize int) ([]byte, error) {
+	if blockSize != 8 {
+		return nil, &Error{"PKCS#5", fmt.Sprintf("block size %d isn't 8", blockSize)}
+	}
+	return pkcs7{name: "PKCS#5"}.Unpad(in, blockSize)
+}
+
+type ansiX923 struct{}
+
+func (ansiX923) Pad(in []byte, blockSize int) []byte {
+	return padWithLength(in, blockSize, func([]byte) {})
+}
+
+func (ansiX923) Unpad(in []byte, blockSize int) ([]byte, error) {
+	n, err := padLength("ANSI X9.23", in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range in[len(in)-n : len(in)-1] {
+		if b != 0 {
+			return nil, &Error{"ANSI X9.23", "nonzero padding bytes"}
+		}
+	}
+	return in[:len(in)-n], nil
+}
+
+// NewISO10126 returns ISO 10126 padding which reads its random bytes from
+// rand, or from crypto/rand if it's nil.
+func NewISO10126(rand io.Reader) Scheme {
+	return iso10126{random.New(rand)}
+}
+
+type iso10126 struct {
+	src *random.Source
+}
+
+func (p iso10126) Pad(in []byte, blockSize int) []byte {
+	return padWithLength(in, blockSize, p.src.Fill)
+}
+
+func (iso10126) Unpad(in []byte, blockSize int) ([]byte, error) {
+	n, err := padLength("ISO 10126", in, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return in[:len(in)-n], nil
+}
+
+type iso7816 struct{}
+
+func (iso7816) Pad(in []byte, blockSize int) []byte {
+	if blockSize < 1 {
+		panic("size can't be less than 1")
+	}
+	n := blockSize - len(in)%blockSize
+	res := make([]byte, len(in)+n)
+	copy(res, in)
+	res[len(in)] = 0x80
+	return res
+}
+
+func (iso7816) Unpad(in []byte, blockSize int) ([]byte, error) {
+	if err := checkBlocks("ISO/IEC 7816-4", in, blockSize); err != nil {
+		return nil, err
+	}
+	i := len(in) - 1
+	for i > len(in)-blockSize && in[i] == 0 {
+		i--
+	}
+	if in[i] != 0x80 {
+		return nil, &Error{"ISO/IEC 7816-4", "no 0x80 marker in the last block"}
+	}
+	return in[:i], nil
+}
+
+type zero struct{}
+
+func (zero) Pad(in []byte, blockSize int) []byte {
+	if blockSize < 1 {
+		panic("size can't be less than 1")
+	}
+	n := (blockSize - len(in)%blockSize) % blockSize
+	res := make([]byte, len(in)+n)
+	copy(res, in)
+	return res
+}
+
+// Unpad never removes a whole block, since padding never adds one: if the
+// last block is all zeros, at least one of them is part of the message.
+func (zero) Unpad(in []byte, blockSize int) ([]byte, error) {
+	if len(in)%blockSize != 0 {
+		return nil, &Error{"zero", "not a whole number of blocks"}
+	}
+	n := len(in)
+	for n > 0 && len(in)-n < blockSize-1 && in[n-1] == 0 {
+		n--
+	}
+	return in[:n], nil
+}
+
+type none struct{}
+
+func (none) Pad(in []byte, blockSize int) []byte {
+	return in
+}
+
+func (none) Unpad(in []byte, blockSize int) ([]byte, error) {
+	return in, nil
+}
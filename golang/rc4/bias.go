@@ -0,0 +1,128 @@
+package rc4
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// NewCookieOracle returns an oracle which encrypts request || cookie with
+// RC4 under a fresh key every time, like a browser sending the same cookie
+// over a new connection. The keys are read from rand, or crypto/rand if
+// it's nil. It's safe to call from several goroutines, although then which
+// request gets which key depends on the order they happen to run in.
+func NewCookieOracle(rand io.Reader, cookie []byte) func([]byte) []byte {
+	src := random.New(rand)
+	var mu sync.Mutex
+	return func(request []byte) []byte {
+		mu.Lock()
+		key := src.Key()
+		mu.Unlock()
+
+		msg := append(append([]byte{}, request...), cookie...)
+		NewCipher(key).XORKeyStream(msg, msg)
+		return msg
+	}
+}
+
+// Bias is a keystream byte which takes a particular value more often than
+// it should.
+type Bias struct {
+	Position int // the position in the keystream, counting from 0
+	Value    byte
+}
+
+// CookieBiases are the biases found by AlFardan et al. in the 16th and
+// 32nd bytes of the keystream, towards 240 and 224.
+var CookieBiases = []Bias{{15, 0xf0}, {31, 0xe0}}
+
+// BiasAttack recovers a secret which is encrypted under lots of
+// different RC4 keys, by lining each byte of it up with biased keystream
+// bytes and seeing which ciphertext byte turns up most often. The fields
+// can be changed, to trade reliability for speed or to try other biases.
+type BiasAttack struct {
+	Biases  []Bias
+	Samples int // encryptions per byte per bias
+	Workers int // goroutines making the encryptions
+}
+
+// NewBiasAttack returns an attack using CookieBiases with enough
+// samples to be reliable, spread across a goroutine per CPU.
+func NewBiasAttack() *BiasAttack {
+	return &BiasAttack{
+		Biases:  CookieBiases,
+		Samples: 1 << 24,
+		Workers: runtime.NumCPU(),
+	}
+}
+
+// RecoverCookie recovers the cookie which oracle appends to requests.
+func (a *BiasAttack) RecoverCookie(oracle func([]byte) []byte) []byte {
+	cookie := make([]byte, len(oracle(nil)))
+
+	for i := range cookie {
+		var scores [256]int
+		used := false
+
+		for _, bias := range a.Biases {
+			// Pad the request so that byte i of the cookie lines up with
+			// the biased byte of the keystream.
+			if bias.Position < i {
+				continue
+			}
+			used = true
+			counts := a.count(oracle, bytes.Repeat([]byte{'/'}, bias.Position-i), bias.Position)
+			for guess := range scores {
+				scores[guess] += counts[byte(guess)^bias.Value]
+			}
+		}
+		if !used {
+			panic(fmt.Sprintf("recoverCookie: no bias covers byte %d", i))
+		}
+
+		for guess := range scores {
+			if scores[guess] > scores[cookie[i]] {
+				cookie[i] = byte(guess)
+			}
+		}
+	}
+
+	return cookie
+}
+
+// count encrypts request a.Samples times, and counts the values the
+// ciphertext byte at position takes.
+func (a *BiasAttack) count(oracle func([]byte) []byte, request []byte, position int) [256]int {
+	workers := a.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make(chan [256]int)
+	for w := 0; w < workers; w++ {
+		n := a.Samples / workers
+		if w < a.Samples%workers {
+			n++
+		}
+		go func(n int) {
+			var counts [256]int
+			for k := 0; k < n; k++ {
+				counts[oracle(request)[position]]++
+			}
+			results <- counts
+		}(n)
+	}
+
+	var total [256]int
+	for w := 0; w < workers; w++ {
+		counts := <-results
+		for v, c := range counts {
+			total[v] += c
+		}
+	}
+	return total
+}
@@ -0,0 +1,42 @@
+// Package rc4 implements the RC4 stream cipher, and recovers secrets from
+// the biases in its keystream.
+package rc4
+
+// Cipher is the RC4 stream cipher, written out by hand so there's nothing
+// hidden between us and the keystream.
+type Cipher struct {
+	s    [256]byte
+	i, j byte
+}
+
+// NewCipher runs the RC4 key schedule for key, which must be between 1 and 256
+// bytes long.
+func NewCipher(key []byte) *Cipher {
+	if len(key) < 1 || len(key) > 256 {
+		panic("NewCipher: invalid key size")
+	}
+
+	c := &Cipher{}
+	for i := range c.s {
+		c.s[i] = byte(i)
+	}
+	var j byte
+	for i := range c.s {
+		j += c.s[i] + key[i%len(key)]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+	}
+	return c
+}
+
+// XORKeyStream XORs src with the keystream and writes the result to dst,
+// which may be the same slice as src.
+func (c *Cipher) XORKeyStream(dst, src []byte) {
+	i, j := c.i, c.j
+	for k, v := range src {
+		i++
+		j += c.s[i]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+		dst[k] = v ^ c.s[c.s[i]+c.s[j]]
+	}
+	c.i, c.j = i, j
+}
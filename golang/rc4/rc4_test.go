@@ -0,0 +1,88 @@
+package rc4
+
+import (
+	"bytes"
+	stdrc4 "crypto/rc4"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestCipher(t *testing.T) {
+	tests := []struct {
+		key, in, expected string
+	}{
+		{"Key", "Plaintext", "bbf316e8d940af0ad3"},
+		{"Wiki", "pedia", "1021bf0420"},
+		{"Secret", "Attack at dawn", "45a01f645fc35b383552544b9bf5"},
+	}
+
+	for _, test := range tests {
+		out := []byte(test.in)
+		NewCipher([]byte(test.key)).XORKeyStream(out, out)
+		testutil.AssertEqual(t, test.expected, hex.EncodeToString(out))
+	}
+
+	// the keystream carries on across calls
+	src := random.New(detrand.New(1))
+	key := src.Key()
+	in := bytes.Repeat([]byte{0x42}, 100)
+	expected := make([]byte, len(in))
+	c, err := stdrc4.NewCipher(key)
+	testutil.FatalIfErr(t, err)
+	c.XORKeyStream(expected, in)
+
+	out := make([]byte, len(in))
+	ours := NewCipher(key)
+	ours.XORKeyStream(out[:33], in[:33])
+	ours.XORKeyStream(out[33:], in[33:])
+	testutil.AssertEqual(t, expected, out)
+}
+
+func TestChallenge56(t *testing.T) {
+	// The biases at positions 16 and 32 need around 2^24 samples per byte
+	// to be reliable, which is too slow for a unit test. The bias towards 0
+	// in the second byte of the keystream is much stronger, so we use that
+	// instead, to recover a short cookie with far fewer samples.
+	cookie := []byte("BE")
+	attack := NewBiasAttack()
+	attack.Biases = []Bias{{1, 0x00}}
+	attack.Samples = 1 << 14
+	// with one worker the oracle hands out its keys in the same order every
+	// time, so the test always sees the same samples
+	attack.Workers = 1
+
+	testutil.AssertEqual(t, string(cookie), string(attack.RecoverCookie(NewCookieOracle(detrand.New(56), cookie))))
+}
+
+func TestCookieBiases(t *testing.T) {
+	// A keystream which is random apart from a strong pull towards the
+	// values CookieBiases expect, at their positions, is enough to check
+	// that the attack lines the cookie up with them and scores the guesses
+	// properly, without the millions of samples real RC4 needs.
+	rand := detrand.New(32)
+	keystream := make([]byte, 64)
+	oracle := func(request []byte) []byte {
+		msg := append(append([]byte{}, request...), "BE"...)
+		rand.Read(keystream)
+		// the end of the keystream is never used, so it can decide
+		// whether each bias shows up this time: a quarter of the time
+		for j, b := range CookieBiases {
+			if keystream[len(keystream)-1-j]%4 == 0 {
+				keystream[b.Position] = b.Value
+			}
+		}
+		for i := range msg {
+			msg[i] ^= keystream[i]
+		}
+		return msg
+	}
+
+	attack := NewBiasAttack()
+	attack.Samples = 1 << 12
+	attack.Workers = 1
+	testutil.AssertEqual(t, "BE", string(attack.RecoverCookie(oracle)))
+}
@@ -0,0 +1,150 @@
+// Package rsa does textbook RSA signatures, and shows how little a valid
+// signature says about which key made it.
+package rsa
+
+import (
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/dlog"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/random"
+)
+
+// Key is an RSA key pair. Signatures are textbook RSA over the SHA-256
+// digest of the message, with no padding. N and E are the public key.
+type Key struct {
+	N, E *big.Int
+	d    *big.Int
+}
+
+// GenerateKey returns a key with a modulus of the given number of bits
+// and e = 65537, with primes made from rand, or crypto/rand if it's nil.
+func GenerateKey(rand io.Reader, bits int) *Key {
+	src := random.New(rand)
+	e := big.NewInt(65537)
+	for {
+		p := src.Prime(bits / 2)
+		q := src.Prime(bits - bits/2)
+		n := new(big.Int).Mul(p, q)
+		if p.Cmp(q) == 0 || n.BitLen() != bits {
+			continue
+		}
+		p.Sub(p, big.NewInt(1))
+		q.Sub(q, big.NewInt(1))
+		if d := new(big.Int).ModInverse(e, p.Mul(p, q)); d != nil {
+			return &Key{N: n, E: e, d: d}
+		}
+	}
+}
+
+// digest is the number which gets signed for msg.
+func digest(msg []byte) *big.Int {
+	h := sha256.Sum256(msg)
+	return new(big.Int).SetBytes(h[:])
+}
+
+// Sign returns the signature of msg.
+func (k *Key) Sign(msg []byte) *big.Int {
+	return new(big.Int).Exp(digest(msg), k.d, k.N)
+}
+
+// Verify says whether sig is a valid signature of msg. It only needs the
+// public part of the key.
+func (k *Key) Verify(msg []byte, sig *big.Int) bool {
+	if sig.Sign() < 0 || sig.Cmp(k.N) >= 0 {
+		return false
+	}
+	return new(big.Int).Exp(sig, k.E, k.N).Cmp(digest(msg)) == 0
+}
+
+// smoothPrime returns a prime p of at least the given number of bits such
+// that p-1 is 2 times a product of distinct odd primes less than limit,
+// along with the prime factors of p-1, starting with 2. None of the odd
+// ones will be in avoid. The primes are picked at random from src.
+func smoothPrime(src *random.Source, bits int, limit int64, avoid map[int64]bool) (p *big.Int, factors []*big.Int) {
+	for {
+		p = big.NewInt(2)
+		factors = factors[:0]
+		used := make(map[int64]bool)
+		for p.BitLen() < bits {
+			r := 3 + src.BigInt(big.NewInt(limit-3)).Int64()
+			if avoid[r] || used[r] || !big.NewInt(r).ProbablyPrime(0) {
+				continue
+			}
+			used[r] = true
+			factors = append(factors, big.NewInt(r))
+			p.Mul(p, big.NewInt(r))
+		}
+		p.Add(p, big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			return p, append([]*big.Int{big.NewInt(2)}, factors...)
+		}
+	}
+}
+
+// isPrimitiveRoot says whether g generates the whole multiplicative group
+// modulo the prime p, given the prime factors of p-1.
+func isPrimitiveRoot(g, p *big.Int, factors []*big.Int) bool {
+	if new(big.Int).Mod(g, p).Sign() == 0 {
+		return false
+	}
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	for _, r := range factors {
+		e := new(big.Int).Div(pm1, r)
+		if new(big.Int).Exp(g, e, p).Cmp(big.NewInt(1)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DSKS finds a new RSA key pair under which sig is also a valid
+// signature of msg. We want e' such that s^e' = m (mod N'), and picking our
+// own primes p and q with smooth p-1 and q-1 makes that discrete log easy:
+// Pohlig-Hellman gets e' modulo p-1 and q-1, and the CRT combines them.
+//
+// Both s and m need to be primitive roots modulo each prime. That makes sure
+// m is a power of s, and also that e' is coprime to (p-1)(q-1) so that
+// there's a matching d'. It also makes e' odd modulo both p-1 and q-1, which
+// share a factor of 2, so the two logs can't disagree about it.
+//
+// The primes are made from rand, or crypto/rand if it's nil.
+func DSKS(rand io.Reader, key *Key, msg []byte, sig *big.Int) *Key {
+	const limit = 1 << 12
+	src := random.New(rand)
+	m := digest(msg)
+	bits := key.N.BitLen()/2 + 1
+
+	prime := func(avoid map[int64]bool) (*big.Int, []*big.Int) {
+		for {
+			p, factors := smoothPrime(src, bits, limit, avoid)
+			if isPrimitiveRoot(sig, p, factors) && isPrimitiveRoot(m, p, factors) {
+				return p, factors
+			}
+		}
+	}
+
+	p, pf := prime(nil)
+	avoid := make(map[int64]bool)
+	for _, r := range pf {
+		avoid[r.Int64()] = true
+	}
+	q, qf := prime(avoid)
+
+	logMod := func(p *big.Int, factors []*big.Int) *big.Int {
+		zp := dlog.ZpGroup{P: p}
+		x, ok := dlog.PohligHellman(zp, new(big.Int).Mod(sig, p), new(big.Int).Mod(m, p), factors)
+		if !ok {
+			panic("DSKS: m is not a power of s")
+		}
+		return x
+	}
+	ep, eq := logMod(p, pf), logMod(q, qf)
+
+	pm1 := new(big.Int).Sub(p, big.NewInt(1))
+	qm1 := new(big.Int).Sub(q, big.NewInt(1))
+	e, _ := dlog.CRT([]*big.Int{ep, eq}, []*big.Int{pm1, new(big.Int).Rsh(qm1, 1)})
+	d := new(big.Int).ModInverse(e, new(big.Int).Mul(pm1, qm1))
+	return &Key{N: new(big.Int).Mul(p, q), E: e, d: d}
+}
@@ -0,0 +1,23 @@
+package rsa
+
+import (
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/detrand"
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge61(t *testing.T) {
+	msg := []byte("I'm the one who signed this")
+	rand := detrand.New(61)
+	key := GenerateKey(rand, 1024)
+	sig := key.Sign(msg)
+	testutil.AssertEqual(t, true, key.Verify(msg, sig))
+
+	eve := DSKS(rand, key, msg, sig)
+	testutil.AssertEqual(t, true, eve.Verify(msg, sig))
+	testutil.AssertEqual(t, true, eve.N.Cmp(key.N) > 0)
+	// Eve's key works for everything else too
+	other := []byte("something else")
+	testutil.AssertEqual(t, true, eve.Verify(other, eve.Sign(other)))
+}
@@ -0,0 +1,23 @@
+package xorcipher
+
+import "math/bits"
+
+// HammingDistance returns the number of bits which differ between a and b.
+func HammingDistance(a, b string) int {
+	return EditDistance([]byte(a), []byte(b))
+}
+
+// EditDistance returns the number of bits which differ between a and b,
+// which must be the same length.
+func EditDistance(a, b []byte) int {
+	dist := 0
+	for i := range a {
+		dist += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return dist
+}
+
+// NormalisedDistance is the edit distance divided by the length.
+func NormalisedDistance(a, b []byte) float64 {
+	return float64(EditDistance(a, b)) / float64(len(a))
+}
@@ -0,0 +1,58 @@
+package xorcipher
+
+import "unicode"
+
+// IsEnglishCharacter says whether c is likely to show up in English text.
+func IsEnglishCharacter(c byte) bool {
+	return englishCharacters[c]
+}
+
+// englishCharacters says, for every byte, whether IsEnglishCharacter would.
+var englishCharacters = func() (t [256]bool) {
+	for c := range t {
+		b := byte(c)
+		t[c] = isAlpha(b) || isSpace(b) || isDigit(b) || isPunctuation(b)
+	}
+	return t
+}()
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlpha(c byte) bool {
+	return isUpper(c) || isLower(c)
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isPunctuation(c byte) bool {
+	switch c {
+	case '\'', '"', '.', ',', ':', ';':
+		return true
+	default:
+		return false
+	}
+}
+
+func isSpace(c byte) bool {
+	return unicode.IsSpace(rune(c))
+}
+
+// ScoreText scores how much buf looks like English: the higher, the better.
+func ScoreText(buf []byte) int {
+	total := 0
+	for _, b := range buf {
+		if IsEnglishCharacter(b) {
+			total++
+		}
+	}
+
+	return total
+}
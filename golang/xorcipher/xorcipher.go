@@ -0,0 +1,195 @@
+// Package xorcipher implements XOR ciphers, and breaks them: single byte
+// XOR and repeating key XOR, using a crude score for how much something
+// looks like English.
+package xorcipher
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// FindSingleByteKey tries every single byte key on a, and returns the
+// decryption which scores best as English, with its score and the key.
+//
+// Since XORing with the key just relabels the bytes, it counts how many of
+// each byte there are once, and scores every key from those counts, so
+// that only the winner's decryption is ever made.
+func FindSingleByteKey(a []byte) (res []byte, score int, key byte) {
+	var counts [256]int
+	for _, c := range a {
+		counts[c]++
+	}
+
+	for guess := 0; guess < 256; guess++ {
+		s := 0
+		for c, n := range counts {
+			if n != 0 && englishCharacters[c^guess] {
+				s += n
+			}
+		}
+		if s > score {
+			score = s
+			key = byte(guess)
+		}
+	}
+	if score > 0 {
+		res = SingleByte(a, key)
+	}
+	return
+}
+
+// FindKeySize guesses the key size of a repeating key XOR ciphertext, by
+// finding the size which gives the smallest normalised edit distance
+// between consecutive blocks.
+func FindKeySize(rawCipher []byte) int {
+	minDistance := math.MaxFloat64
+	var keySize int
+	n := len(rawCipher)
+
+	for keyLen := 2; keyLen <= 40; keyLen++ {
+		blockCount := n/keyLen - 1
+		totalDistance := 0.0
+
+		for i := 0; i < blockCount; i++ {
+			offset := i * keyLen
+			first := rawCipher[offset : offset+keyLen]
+			second := rawCipher[offset+keyLen : offset+2*keyLen]
+			totalDistance += NormalisedDistance(first, second)
+		}
+
+		avgDistance := totalDistance / float64(blockCount)
+
+		if minDistance > avgDistance {
+			minDistance = avgDistance
+			keySize = keyLen
+		}
+	}
+
+	return keySize
+}
+
+// FindRepeatingKey recovers the key of a repeating key XOR ciphertext,
+// given the key size.
+func FindRepeatingKey(in []byte, keySize int) []byte {
+
+	// Now that you probably know the KEYSIZE: break the ciphertext into blocks of KEYSIZE length.
+
+	// Now transpose the blocks: make a block that is the first byte of every block, and a block
+	// that is the second byte of every block, and so on.
+
+	// We have cipher text 1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef
+	// Treat it as:
+	// 1234567890abcdef
+	// 1234567890abcdef
+	// 1234567890abcdef
+	// 1234567890abcdef
+	// 1234567890abcdef
+
+	// So we make a block containing the each column, and solve single Key XOR for that.
+
+	// Solve each block as if it was single-character XOR. You already have code to do this.
+
+	// For each block, the single-byte XOR key that produces the best looking histogram is the
+	// repeating-key XOR key byte for that block. Put them together and you have the key.
+	column := make([]byte, (len(in)+keySize-1)/keySize)
+	key := make([]byte, keySize)
+
+	for col := 0; col < keySize; col++ {
+		for row := range column {
+			if row*keySize+col >= len(in) {
+				continue
+			}
+			column[row] = in[row*keySize+col]
+		}
+		_, _, k := FindSingleByteKey(column)
+		key[col] = k
+	}
+
+	return key
+
+}
+
+// XOR returns a XOR b, which is as long as the shorter of the two.
+func XOR(a, b []byte) []byte {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	res := make([]byte, n)
+	XORBytes(res, a, b)
+	return res
+}
+
+// RepeatingKey encrypts (or decrypts) in with repeating key XOR.
+func RepeatingKey(in, key []byte) []byte {
+	res := make([]byte, len(in))
+	XORRepeatingKey(res, in, key)
+	return res
+}
+
+// SingleByte XORs every byte of in with b.
+func SingleByte(in []byte, b byte) []byte {
+	res := make([]byte, len(in))
+	XORSingleByte(res, in, b)
+	return res
+}
+
+// wordSize is how many bytes the XOR functions deal with at once.
+const wordSize = 8
+
+// XORBytes sets dst to a XOR b, for as many bytes as the shorter of a and b
+// has, and returns how many that was. It's XOR without the allocation, and
+// works a 64 bit word at a time rather than a byte at a time. dst can be a
+// or b, for XORing in place, but mustn't otherwise overlap them.
+func XORBytes(dst, a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if len(dst) < n {
+		panic("xorcipher: dst too short")
+	}
+	dst, a, b = dst[:n], a[:n], b[:n]
+
+	i := 0
+	for ; i+wordSize <= n; i += wordSize {
+		w := binary.LittleEndian.Uint64(a[i:]) ^ binary.LittleEndian.Uint64(b[i:])
+		binary.LittleEndian.PutUint64(dst[i:], w)
+	}
+	for ; i < n; i++ {
+		dst[i] = a[i] ^ b[i]
+	}
+	return n
+}
+
+// XORSingleByte is SingleByte, writing to dst instead of allocating. dst
+// can be in.
+func XORSingleByte(dst, in []byte, b byte) {
+	if len(dst) < len(in) {
+		panic("xorcipher: dst too short")
+	}
+	dst = dst[:len(in)]
+
+	w := uint64(b) * 0x0101010101010101
+	i := 0
+	for ; i+wordSize <= len(in); i += wordSize {
+		binary.LittleEndian.PutUint64(dst[i:], binary.LittleEndian.Uint64(in[i:])^w)
+	}
+	for ; i < len(in); i++ {
+		dst[i] = in[i] ^ b
+	}
+}
+
+// XORRepeatingKey is RepeatingKey, writing to dst instead of allocating.
+// dst can be in. It panics if key is empty and there's anything to XOR.
+func XORRepeatingKey(dst, in, key []byte) {
+	if len(dst) < len(in) {
+		panic("xorcipher: dst too short")
+	}
+	if len(key) == 0 && len(in) > 0 {
+		panic("xorcipher: empty key")
+	}
+	for i := 0; i < len(in); i += len(key) {
+		XORBytes(dst[i:], in[i:], key)
+	}
+}
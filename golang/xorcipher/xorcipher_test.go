@@ -0,0 +1,195 @@
+package xorcipher
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/jabley/matasano-crypto-challenges/golang/internal/testutil"
+)
+
+func TestChallenge2(t *testing.T) {
+	out := XOR(
+		testutil.DecodeHex(t, "1c0111001f010100061a024b53535009181c"),
+		testutil.DecodeHex(t, "686974207468652062756c6c277320657965"))
+	testutil.AssertEqual(t, testutil.DecodeHex(t, "746865206b696420646f6e277420706c6179"), out)
+}
+
+func TestChallenge3(t *testing.T) {
+	out, _, _ := FindSingleByteKey(testutil.DecodeHex(t, "1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736"))
+	testutil.AssertEqual(t, "Cooking MC's like a pound of bacon", string(out))
+}
+
+func TestChallenge4(t *testing.T) {
+	in := string(testutil.ReadFile(t, "../../inputs/4.txt"))
+	bestScore := 0
+	var out []byte
+
+	for _, hs := range strings.Split(in, "\n") {
+		candidate, score, _ := FindSingleByteKey(testutil.DecodeHex(t, hs))
+		if score > bestScore {
+			bestScore = score
+			out = candidate
+		}
+	}
+	testutil.AssertEqual(t, "Now that the party is jumping\n", string(out))
+}
+
+func TestChallenge5(t *testing.T) {
+	in := []byte("Burning 'em, if you ain't quick and nimble\nI go crazy when I hear a cymbal")
+	key := []byte{'I', 'C', 'E'}
+	dst := RepeatingKey(in, key)
+	testutil.AssertEqual(t, "0b3637272a2b2e63622c2e69692a23693a2a3c6324202d623d63343c"+
+		"2a26226324272765272a282b2f20430a652e2c652a3124333a653e2b"+
+		"2027630c692b20283165286326302e27282f", hex.EncodeToString(dst))
+}
+
+func TestChallenge6(t *testing.T) {
+	testutil.AssertEqual(t, 37, HammingDistance("this is a test", "wokka wokka!!!"))
+	text := testutil.DecodeBase64(t, string(testutil.ReadFile(t, "../../inputs/6.txt")))
+	keySize := FindKeySize(text)
+	key := FindRepeatingKey(text, keySize)
+	out := RepeatingKey(text, key)
+
+	expected := testutil.ReadFile(t, "../../outputs/6.txt")
+	testutil.AssertEqual(t, string(expected), string(out))
+}
+
+// xorBytewise is how XORBytes used to work, for checking against and
+// benchmarking.
+func xorBytewise(dst, a, b []byte) {
+	for i := range a {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// findSingleByteKeyNaive is how FindSingleByteKey used to work, making
+// and scoring every candidate decryption.
+func findSingleByteKeyNaive(a []byte) (res []byte, score int, key byte) {
+	for guess := 0; guess < 256; guess++ {
+		out := SingleByte(a, byte(guess))
+		s := ScoreText(out)
+		if s > score {
+			score = s
+			key = byte(guess)
+			res = out
+		}
+	}
+	return
+}
+
+func TestXORBytes(t *testing.T) {
+	a, b := make([]byte, 40), make([]byte, 40)
+	for i := range a {
+		a[i], b[i] = byte(7*i+1), byte(13*i+5)
+	}
+	for n := 0; n <= len(a); n++ {
+		for off := 0; off < 3 && off <= n; off++ {
+			expected := make([]byte, n-off)
+			xorBytewise(expected, a[off:n], b[:n-off])
+			dst := make([]byte, n-off)
+			testutil.AssertEqual(t, n-off, XORBytes(dst, a[off:n], b))
+			testutil.AssertEqual(t, expected, dst)
+
+			// in place
+			in := make([]byte, n-off)
+			copy(in, a[off:n])
+			XORBytes(in, in, b)
+			testutil.AssertEqual(t, expected, in)
+		}
+
+		key := []byte("ICE")
+		expected := make([]byte, n)
+		for i := range expected {
+			expected[i] = a[i] ^ key[i%len(key)]
+		}
+		dst := make([]byte, n)
+		XORRepeatingKey(dst, a[:n], key)
+		testutil.AssertEqual(t, expected, dst)
+
+		for i := range expected {
+			expected[i] = a[i] ^ 0x5a
+		}
+		XORSingleByte(dst, a[:n], 0x5a)
+		testutil.AssertEqual(t, expected, dst)
+	}
+
+	testutil.AssertEqual(t, 0.0, testing.AllocsPerRun(10, func() {
+		XORBytes(a, a, b)
+		XORSingleByte(a, a, 1)
+		XORRepeatingKey(a, a, b[:3])
+	}))
+
+	// an empty key has nothing to repeat
+	XORRepeatingKey(nil, nil, nil)
+	func() {
+		defer func() {
+			testutil.AssertEqual(t, "xorcipher: empty key", recover())
+		}()
+		RepeatingKey([]byte("ICE"), nil)
+	}()
+}
+
+func TestFindSingleByteKey(t *testing.T) {
+	in := string(testutil.ReadFile(t, "../../inputs/4.txt"))
+	for _, hs := range strings.Split(in, "\n") {
+		line := testutil.DecodeHex(t, hs)
+		res, score, key := FindSingleByteKey(line)
+		expectedRes, expectedScore, expectedKey := findSingleByteKeyNaive(line)
+		testutil.AssertEqual(t, expectedRes, res)
+		testutil.AssertEqual(t, expectedScore, score)
+		testutil.AssertEqual(t, expectedKey, key)
+	}
+}
+
+func BenchmarkXOR(b *testing.B) {
+	x, y, dst := make([]byte, 4096), make([]byte, 4096), make([]byte, 4096)
+	b.Run("bytewise", func(b *testing.B) {
+		b.SetBytes(int64(len(x)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			xorBytewise(dst, x, y)
+		}
+	})
+	b.Run("allocating", func(b *testing.B) {
+		b.SetBytes(int64(len(x)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			XOR(x, y)
+		}
+	})
+	b.Run("XORBytes", func(b *testing.B) {
+		b.SetBytes(int64(len(x)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			XORBytes(dst, x, y)
+		}
+	})
+}
+
+func BenchmarkFindSingleByteKey(b *testing.B) {
+	text := testutil.ReadFile(b, "../../outputs/6.txt")
+	in := SingleByte(text, 'X')
+	b.Run("naive", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			findSingleByteKeyNaive(in)
+		}
+	})
+	b.Run("counts", func(b *testing.B) {
+		b.SetBytes(int64(len(in)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			FindSingleByteKey(in)
+		}
+	})
+}
+
+func BenchmarkBreakRepeatingKey(b *testing.B) {
+	text := testutil.DecodeBase64(b, string(testutil.ReadFile(b, "../../inputs/6.txt")))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FindRepeatingKey(text, FindKeySize(text))
+	}
+}